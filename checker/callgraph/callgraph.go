@@ -1,11 +1,11 @@
 package callgraph
 
 import (
-	"log"
-	"strings"
+	"errors"
+	"reflect"
+	"sort"
 	"sync"
 
-	"github.com/hyunsooda/paramguard/checker/passtyps"
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/callgraph"
 	"golang.org/x/tools/go/callgraph/cha"
@@ -15,97 +15,188 @@ import (
 	"golang.org/x/tools/go/ssa/ssautil"
 )
 
-type FromTo struct {
-	from string
-	to   string
-}
+// DefaultMaxpath is the maximum number of callers on a call path, unless configured
+const DefaultMaxpath = 30
+
+// maxPaths bounds the number of call paths of a function
+const maxPaths = 30
 
-const DefaultNPaths = 30
+// Analyzer provides the call graph of the program as a *Graph, built on its first use.
+// The analysis framework runs it package by package, so it loads the packages under the current directory,
+// once per process, whereas the driver provides the graph of the packages of each run instead.
+var Analyzer = &analysis.Analyzer{
+	Name:       "callgraph",
+	Doc:        "Provide the call graph of the packages under the current directory",
+	Run:        run,
+	ResultType: reflect.TypeOf((*Graph)(nil)),
+}
 
 var (
-	CGP  passtyps.CallGraph
-	once = new(sync.Once)
+	wdGraph *Graph
+	wdOnce  sync.Once
 )
 
-func getProjPkg(pkg *ssa.Package) string {
-	proj := strings.Split(pkg.String(), "/")
-	return strings.Join(proj[:3], "/")
+func run(pass *analysis.Pass) (interface{}, error) {
+	wdOnce.Do(func() {
+		wdGraph = Load(&packages.Config{Mode: packages.LoadAllSyntax}, "./...")
+	})
+	return wdGraph, nil
 }
 
-func InitCallGraph(p *analysis.Pass) {
-	if passtyps.Testing.On {
-		return
+// Graph is the call graph of the loaded packages, giving the call paths to their functions
+type Graph struct {
+	load func() ([]*packages.Package, error)
+	once sync.Once
+	err  error
+
+	callers map[string][]string // the functions of the packages calling each of them, sorted by name
+
+	mu    sync.Mutex
+	paths map[pathsKey][][]string
+}
+
+type pathsKey struct {
+	fn     string
+	maxLen int
+}
+
+// Load returns the graph of the packages matching the patterns, loaded with `cfg` on the first use of the graph
+func Load(cfg *packages.Config, patterns ...string) *Graph {
+	return &Graph{load: func() ([]*packages.Package, error) {
+		initial, err := packages.Load(cfg, patterns...)
+		if err != nil {
+			return nil, err
+		}
+		if packages.PrintErrors(initial) > 0 {
+			return nil, errors.New("packages contain errors")
+		}
+		return initial, nil
+	}}
+}
+
+// New returns the graph of the packages loaded with their dependencies and syntax, e.g., packages.LoadAllSyntax
+func New(initial []*packages.Package) *Graph {
+	return &Graph{load: func() ([]*packages.Package, error) {
+		return initial, nil
+	}}
+}
+
+// CallPaths returns the paths of the functions of the packages calling the function `fn`, e.g., "pkg.F" or
+// "(*pkg.T).M", each from its direct caller outward. A path ends at a function without callers, before
+// a recursive call, or at `maxLen` callers, and the paths follow the order of the names of the callers.
+func (g *Graph) CallPaths(fn string, maxLen int) ([][]string, error) {
+	g.once.Do(g.build)
+	if g.err != nil {
+		return nil, g.err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if paths, ok := g.paths[pathsKey{fn, maxLen}]; ok {
+		return paths, nil
 	}
-	config := passtyps.ParseConfig(p)
-	if config.CallGraph {
-		nPaths := config.Maxpath
-		if nPaths == 0 {
-			nPaths = DefaultNPaths
+
+	var paths [][]string
+	var path []string
+	onPath := map[string]bool{fn: true}
+	var visit func(name string)
+	visit = func(name string) {
+		if len(paths) == maxPaths {
+			return
+		}
+		var next []string
+		if len(path) < maxLen {
+			for _, caller := range g.callers[name] {
+				if !onPath[caller] {
+					next = append(next, caller)
+				}
+			}
+		}
+		if len(next) == 0 {
+			if len(path) > 0 {
+				paths = append(paths, append([]string(nil), path...))
+			}
+			return
+		}
+		for _, caller := range next {
+			path = append(path, caller)
+			onPath[caller] = true
+			visit(caller)
+			path = path[:len(path)-1]
+			delete(onPath, caller)
 		}
-		once.Do(func() {
-			CGP = genCallGraph(nPaths)
-		})
 	}
+	visit(fn)
+	g.paths[pathsKey{fn, maxLen}] = paths
+	return paths, nil
 }
 
-/// genCallGraph returns callpaths for all of the callees.
-/// Return format is (callee, [caller of callee (P), caller of P (PP), caller of PP, ...])
-/// For N of different callers, they are represented with the array
-func genCallGraph(max int) passtyps.CallGraph {
-	cfg := &packages.Config{
-		Mode:  packages.LoadAllSyntax,
-		Tests: false,
-		Dir:   "",
-	}
-	initial, err := packages.Load(cfg, "./...")
+func (g *Graph) build() {
+	initial, err := g.load()
 	if err != nil {
-		log.Fatalln(err)
+		g.err = err
+		return
+	}
+	own := make(map[string]bool)
+	for _, pkg := range initial {
+		own[pkg.PkgPath] = true
 	}
-	if packages.PrintErrors(initial) > 0 {
-		log.Fatalln("packages contain errors")
+	// A function of the loaded packages, or an instance of one
+	isOwn := func(fn *ssa.Function) bool {
+		if origin := fn.Origin(); origin != nil {
+			fn = origin
+		}
+		return fn.Synthetic == "" && fn.Pkg != nil && own[fn.Pkg.Pkg.Path()]
 	}
+
 	// Create and build SSA-form program representation
 	mode := ssa.InstantiateGenerics // instantiate generics by default for soundness
-	prog, pkgs := ssautil.AllPackages(initial, mode)
+	prog, _ := ssautil.AllPackages(initial, mode)
 	prog.Build()
-
-	proj := getProjPkg(pkgs[0])
 	cg := vta.CallGraph(ssautil.AllFunctions(prog), cha.CallGraph(prog))
 
-	callPaths := make(passtyps.CallGraph)
-	callgraph.GraphVisitEdges(cg, func(e *callgraph.Edge) error {
-		callerFunc := e.Caller.Func
-		callee, calleeFunc := e.Callee, e.Callee.Func
-		if callerFunc.Pkg != nil &&
-			calleeFunc.Pkg != nil &&
-			strings.Contains(callerFunc.Pkg.String(), proj) &&
-			strings.Contains(calleeFunc.Pkg.String(), proj) {
-			var visited []string
-			visitIn(callee, &visited, max-1)
-			callPaths[calleeFunc.String()] = visited
+	callers := make(map[string]map[string]bool)
+	for fn, node := range cg.Nodes {
+		if fn == nil || !isOwn(fn) {
+			continue
 		}
-		return nil
-	})
-	return callPaths
-}
-
-func visitIn(n *callgraph.Node, visited *[]string, max int) {
-	if len(*visited) > max {
-		return
-	}
-	vs := make([]string, len(n.In))
-	for idx, in := range n.In {
-		vs[idx] = in.Caller.Func.String()
+		name := funcName(fn)
+		if callers[name] == nil {
+			callers[name] = make(map[string]bool)
+		}
+		// The synthetic wrappers, e.g., of method values, pass the calls through
+		seen := map[*callgraph.Node]bool{node: true}
+		var visit func(n *callgraph.Node)
+		visit = func(n *callgraph.Node) {
+			for _, in := range n.In {
+				caller := in.Caller
+				if caller.Func == nil || seen[caller] {
+					continue
+				}
+				seen[caller] = true
+				if isOwn(caller.Func) {
+					callers[name][funcName(caller.Func)] = true
+				} else if caller.Func.Synthetic != "" {
+					visit(caller)
+				}
+			}
+		}
+		visit(node)
 	}
-	switch len(n.In) {
-	case 0:
-		break
-	case 1:
-		*visited = append(*visited, vs[0])
-	default:
-		*visited = append(*visited, "["+strings.Join(vs, ", ")+"]")
+
+	g.callers = make(map[string][]string, len(callers))
+	for name, set := range callers {
+		for caller := range set {
+			g.callers[name] = append(g.callers[name], caller)
+		}
+		sort.Strings(g.callers[name])
 	}
-	for _, in := range n.In {
-		visitIn(in.Caller, visited, max)
+	g.paths = make(map[pathsKey][][]string)
+}
+
+// funcName names the function as go/types does, the generic one for an instance
+func funcName(fn *ssa.Function) string {
+	if origin := fn.Origin(); origin != nil {
+		fn = origin
 	}
+	return fn.String()
 }
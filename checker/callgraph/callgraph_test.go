@@ -0,0 +1,54 @@
+package callgraph_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hyunsooda/paramguard/checker/callgraph"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestCallPaths(t *testing.T) {
+	testdata, err := filepath.Abs(filepath.Join("..", "passes", "testdata"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &packages.Config{
+		Mode: packages.LoadAllSyntax,
+		Dir:  filepath.Join(testdata, "src", "callpaths"),
+		Env:  append(os.Environ(), "GOPATH="+testdata, "GO111MODULE=off", "GOPROXY=off"),
+	}
+
+	tcs := []struct {
+		fn     string
+		maxLen int
+		paths  [][]string
+	}{
+		{"callpaths.use", callgraph.DefaultMaxpath, [][]string{
+			{"callpaths.a", "callpaths.main"},
+			{"callpaths.b", "callpaths.c"},
+			{"callpaths.b", "callpaths.main"},
+			{"callpaths.recursive", "callpaths.main"},
+		}},
+		{"callpaths.use", 1, [][]string{{"callpaths.a"}, {"callpaths.b"}, {"callpaths.recursive"}}},
+		{"callpaths.recursive", callgraph.DefaultMaxpath, [][]string{{"callpaths.main"}}},
+		{"callpaths.main", callgraph.DefaultMaxpath, nil},
+	}
+	graph := callgraph.Load(cfg, ".")
+	for _, tc := range tcs {
+		paths, err := graph.CallPaths(tc.fn, tc.maxLen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(paths, tc.paths) {
+			t.Errorf("%s: %v, want %v", tc.fn, paths, tc.paths)
+		}
+	}
+
+	cfg.Dir = filepath.Join(testdata, "src", "missing")
+	if _, err := callgraph.Load(cfg, ".").CallPaths("callpaths.use", 1); err == nil {
+		t.Error("no error loading a missing package")
+	}
+}
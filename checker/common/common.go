@@ -2,6 +2,8 @@ package common
 
 import (
 	"go/ast"
+	"go/constant"
+	"go/token"
 	"go/types"
 
 	"github.com/hyunsooda/paramguard/checker/passtyps"
@@ -14,50 +16,32 @@ func UnwrapPtrTyp(typ types.Type) types.Type {
 	return typ
 }
 
-func MapMerge(m1, m2 passtyps.NamedTypes) passtyps.NamedTypes {
-	m := make(map[types.Type]map[*ast.Ident]types.Object)
-	for declTyp, structTyp := range m1 {
-		m[declTyp] = make(map[*ast.Ident]types.Object)
-		for ident, obj := range structTyp {
-			m[declTyp][ident] = obj
-		}
-	}
-	for declTyp, structTyp := range m2 {
-		if m[declTyp] == nil {
-			m[declTyp] = make(map[*ast.Ident]types.Object)
-		}
-		for ident, obj := range structTyp {
-			m[declTyp][ident] = obj
-		}
-	}
-	return m
-}
-
-func IsCollected(collected passtyps.NamedTypes, target types.Object) bool {
-	for _, structTyp := range collected {
-		for _, obj := range structTyp {
-			if obj == target {
-				return true
-			}
-		}
-	}
-	return false
-}
-
 func IsTargetedParam(ctx passtyps.Context, expr ast.Expr) types.Object {
 	obj := ctx.Pass.TypesInfo.ObjectOf(Cast2Ident(expr))
 	if obj == nil {
 		return nil
 	}
-	if v := findParam(obj.Type(), ctx.Params); v != nil && v.Type().String() != "*testing.T" {
+	if param, ok := ctx.Aliases[obj]; ok {
+		obj = param
+	}
+	if v := findParam(obj, ctx.Params); v != nil && !isTestingHelper(v) {
 		return v
 	}
 	return nil
 }
 
-func findParam(objTyp types.Type, params []types.Object) types.Object {
+// isTestingHelper reports whether the parameter is the test handle passed by the testing package
+func isTestingHelper(param types.Object) bool {
+	switch param.Type().String() {
+	case "*testing.T", "*testing.B", "*testing.F", "testing.TB":
+		return true
+	}
+	return false
+}
+
+func findParam(obj types.Object, params []types.Object) types.Object {
 	for _, param := range params {
-		if objTyp == param.Type() {
+		if obj == param {
 			return param
 		}
 	}
@@ -81,8 +65,98 @@ func GetSelectorExprChildren(expr ast.Expr) []*ast.SelectorExpr {
 	return children
 }
 
+// MemberPath splits a selector chain (e.g., `p.a.b`) into its root expression and the accessed members
+func MemberPath(info *types.Info, expr ast.Expr) (ast.Expr, []types.Object) {
+	children := GetSelectorExprChildren(expr)
+	if len(children) == 0 {
+		return expr, nil
+	}
+	path := make([]types.Object, len(children))
+	for i, child := range children {
+		path[len(children)-1-i] = info.Uses[child.Sel]
+	}
+	return children[len(children)-1].X, path
+}
+
+// IsNilableTyp reports whether the zero value of the type is nil.
+// A type parameter is nilable only if every type in its type set is, e.g., `[P ~*int | ~*string]`.
+func IsNilableTyp(typ types.Type) bool {
+	if tp, ok := typ.(*types.TypeParam); ok {
+		terms, bounded := typeSet(tp.Constraint())
+		if !bounded || len(terms) == 0 {
+			return false // e.g., `any` admits int
+		}
+		for _, term := range terms {
+			if !IsNilableTyp(term) {
+				return false
+			}
+		}
+		return true
+	}
+	switch typ.Underlying().(type) {
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan, *types.Signature, *types.Interface:
+		return true
+	}
+	return false
+}
+
+// CoreTyp returns the underlying type of `typ`, or for a type parameter, the underlying type shared by
+// every type in its type set (e.g., the map of `[M ~map[K]V]`). It returns nil if there is none (e.g., `any`).
+func CoreTyp(typ types.Type) types.Type {
+	tp, ok := typ.(*types.TypeParam)
+	if !ok {
+		return typ.Underlying()
+	}
+	terms, bounded := typeSet(tp.Constraint())
+	if !bounded || len(terms) == 0 {
+		return nil
+	}
+	for _, term := range terms[1:] {
+		if !types.Identical(term, terms[0]) {
+			return nil
+		}
+	}
+	return terms[0]
+}
+
+// typeSet returns the underlying types of the terms restricting a constraint.
+// An unbounded type set (e.g., `any`, `comparable`, `interface{ String() string }`) reports false.
+func typeSet(constraint types.Type) ([]types.Type, bool) {
+	iface, ok := constraint.Underlying().(*types.Interface)
+	if !ok {
+		return nil, false
+	}
+	var terms []types.Type
+	bounded := false
+	for i := 0; i < iface.NumEmbeddeds(); i++ {
+		switch embedded := iface.EmbeddedType(i).(type) {
+		case *types.Union:
+			for j := 0; j < embedded.Len(); j++ {
+				terms = append(terms, CoreTyp(embedded.Term(j).Type()))
+			}
+			bounded = true
+		default:
+			if _, ok := embedded.Underlying().(*types.Interface); ok {
+				inner, innerBounded := typeSet(embedded)
+				if innerBounded {
+					terms = append(terms, inner...)
+					bounded = true
+				}
+				continue
+			}
+			terms = append(terms, embedded.Underlying()) // e.g., `interface{ []byte }`
+			bounded = true
+		}
+	}
+	return terms, bounded
+}
+
+func IsErrorTyp(typ types.Type) bool {
+	return types.Identical(typ, types.Universe.Lookup("error").Type())
+}
+
 func IsSliceTyp(paramTyp types.Object) bool {
-	_, ok := paramTyp.Type().Underlying().(*types.Slice)
+	_, ok := CoreTyp(paramTyp.Type()).(*types.Slice)
 	return ok
 }
 
@@ -98,3 +172,50 @@ func Cast2Ident(expr ast.Expr) *ast.Ident {
 		return nil
 	}
 }
+
+func IsNilIdent(expr ast.Expr) bool {
+	ident := Cast2Ident(expr)
+	return ident != nil && ident.Name == "nil"
+}
+
+func ConstInt(info *types.Info, expr ast.Expr) (int64, bool) {
+	tv, ok := info.Types[expr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.Int {
+		return 0, false
+	}
+	return constant.Int64Val(tv.Value)
+}
+
+// NegateOp returns the comparison holding when `x <op> y` does not hold
+func NegateOp(op token.Token) token.Token {
+	switch op {
+	case token.EQL:
+		return token.NEQ
+	case token.NEQ:
+		return token.EQL
+	case token.LSS:
+		return token.GEQ
+	case token.GEQ:
+		return token.LSS
+	case token.GTR:
+		return token.LEQ
+	case token.LEQ:
+		return token.GTR
+	}
+	return token.ILLEGAL
+}
+
+// MirrorOp returns the comparison equivalent to `x <op> y` with its operands swapped
+func MirrorOp(op token.Token) token.Token {
+	switch op {
+	case token.LSS:
+		return token.GTR
+	case token.GTR:
+		return token.LSS
+	case token.LEQ:
+		return token.GEQ
+	case token.GEQ:
+		return token.LEQ
+	}
+	return op
+}
@@ -0,0 +1,239 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"go/types"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hyunsooda/paramguard/checker/callgraph"
+	"github.com/hyunsooda/paramguard/checker/passes"
+	"github.com/hyunsooda/paramguard/checker/passtyps"
+	"github.com/hyunsooda/paramguard/checker/report"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+)
+
+// Config locates the packages to analyze and configures the checker
+type Config struct {
+	Dir     string           // directory to load the packages from, the current directory if empty
+	Env     []string         // environment of the build system, the current one if nil
+	Checker *passtyps.Config // configuration of the checker, read from the flags if nil
+}
+
+type objFactKey struct {
+	obj types.Object
+	typ reflect.Type
+}
+
+type pkgFactKey struct {
+	pkg *types.Package
+	typ reflect.Type
+}
+
+// runner runs the analyzers over the packages in dependency order, sharing the facts exported on the way,
+// the call graph of the matched packages and the configuration of the run
+type runner struct {
+	objFacts map[objFactKey]analysis.Fact
+	pkgFacts map[pkgFactKey]analysis.Fact
+	graph    *callgraph.Graph
+	config   *passtyps.Config
+}
+
+// Run loads the packages matching the patterns and runs the checker over them and their dependencies,
+// returning the findings of the matched packages sorted by position
+func Run(ctx context.Context, config Config, patterns ...string) ([]*report.Finding, error) {
+	if config.Checker != nil {
+		if err := config.Checker.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg := &packages.Config{
+		Context: ctx,
+		Mode:    packages.LoadAllSyntax,
+		Dir:     config.Dir,
+		Env:     config.Env,
+	}
+	initial, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	var errs []string
+	packages.Visit(initial, nil, func(pkg *packages.Package) {
+		for _, err := range pkg.Errors {
+			errs = append(errs, err.Error())
+		}
+	})
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("packages contain errors:\n%s", strings.Join(errs, "\n"))
+	}
+
+	roots := make(map[*packages.Package]bool)
+	for _, pkg := range initial {
+		roots[pkg] = true
+	}
+	r := &runner{
+		objFacts: make(map[objFactKey]analysis.Fact),
+		pkgFacts: make(map[pkgFactKey]analysis.Fact),
+		graph:    callgraph.New(initial),
+		config:   config.Checker,
+	}
+	var findings []*report.Finding
+	packages.Visit(initial, nil, func(pkg *packages.Package) {
+		if err == nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			return
+		}
+		var results map[*analysis.Analyzer]interface{}
+		results, err = r.runPkg(pkg, roots[pkg])
+		if roots[pkg] && err == nil {
+			findings = append(findings, results[passes.MainAnalyzer].([]*report.Finding)...)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i].Pos, findings[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return findings, nil
+}
+
+// runPkg runs the analyzers over `pkg`: all of them on a root package,
+// only those exporting facts (and their requirements) on a dependency
+func (r *runner) runPkg(pkg *packages.Package, root bool) (map[*analysis.Analyzer]interface{}, error) {
+	// The graph of the run replaces the one of the packages under the current directory,
+	// and its configuration the one read from the flags
+	results := map[*analysis.Analyzer]interface{}{callgraph.Analyzer: r.graph}
+	if r.config != nil {
+		results[passes.ConfigCollector] = r.config
+	}
+	var run func(a *analysis.Analyzer) error
+	run = func(a *analysis.Analyzer) error {
+		if _, done := results[a]; done {
+			return nil
+		}
+		for _, req := range a.Requires {
+			if err := run(req); err != nil {
+				return err
+			}
+		}
+		pass := r.newPass(a, pkg, results)
+		result, err := a.Run(pass)
+		if err != nil {
+			return fmt.Errorf("%s: %s: %v", a.Name, pkg.PkgPath, err)
+		}
+		results[a] = result
+		return nil
+	}
+
+	if root {
+		return results, run(passes.MainAnalyzer)
+	}
+	for _, a := range factAnalyzers(passes.MainAnalyzer) {
+		if err := run(a); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// factAnalyzers returns the analyzers exporting facts that `a` depends on, including itself
+func factAnalyzers(a *analysis.Analyzer) []*analysis.Analyzer {
+	var analyzers []*analysis.Analyzer
+	seen := make(map[*analysis.Analyzer]bool)
+	var visit func(a *analysis.Analyzer)
+	visit = func(a *analysis.Analyzer) {
+		if seen[a] {
+			return
+		}
+		seen[a] = true
+		for _, req := range a.Requires {
+			visit(req)
+		}
+		if len(a.FactTypes) > 0 {
+			analyzers = append(analyzers, a)
+		}
+	}
+	visit(a)
+	return analyzers
+}
+
+func (r *runner) newPass(a *analysis.Analyzer, pkg *packages.Package, results map[*analysis.Analyzer]interface{}) *analysis.Pass {
+	resultOf := make(map[*analysis.Analyzer]interface{}, len(a.Requires))
+	for _, req := range a.Requires {
+		resultOf[req] = results[req]
+	}
+	return &analysis.Pass{
+		Analyzer:     a,
+		Fset:         pkg.Fset,
+		Files:        pkg.Syntax,
+		OtherFiles:   pkg.OtherFiles,
+		IgnoredFiles: pkg.IgnoredFiles,
+		Pkg:          pkg.Types,
+		TypesInfo:    pkg.TypesInfo,
+		TypesSizes:   pkg.TypesSizes,
+		ResultOf:     resultOf,
+		Report:       func(analysis.Diagnostic) {}, // the findings are the result of the checker
+		ImportObjectFact: func(obj types.Object, fact analysis.Fact) bool {
+			return importFact(r.objFacts[objFactKey{obj, reflect.TypeOf(fact)}], fact)
+		},
+		ImportPackageFact: func(pkg *types.Package, fact analysis.Fact) bool {
+			return importFact(r.pkgFacts[pkgFactKey{pkg, reflect.TypeOf(fact)}], fact)
+		},
+		ExportObjectFact: func(obj types.Object, fact analysis.Fact) {
+			r.objFacts[objFactKey{obj, reflect.TypeOf(fact)}] = fact
+		},
+		ExportPackageFact: func(fact analysis.Fact) {
+			r.pkgFacts[pkgFactKey{pkg.Types, reflect.TypeOf(fact)}] = fact
+		},
+		AllObjectFacts: func() []analysis.ObjectFact {
+			var facts []analysis.ObjectFact
+			for key, fact := range r.objFacts {
+				if ownsFact(a, key.typ) {
+					facts = append(facts, analysis.ObjectFact{Object: key.obj, Fact: fact})
+				}
+			}
+			return facts
+		},
+		AllPackageFacts: func() []analysis.PackageFact {
+			var facts []analysis.PackageFact
+			for key, fact := range r.pkgFacts {
+				if ownsFact(a, key.typ) {
+					facts = append(facts, analysis.PackageFact{Package: key.pkg, Fact: fact})
+				}
+			}
+			return facts
+		},
+	}
+}
+
+// importFact copies the exported fact into `fact`, a pointer of the same type
+func importFact(exported, fact analysis.Fact) bool {
+	if exported == nil {
+		return false
+	}
+	reflect.ValueOf(fact).Elem().Set(reflect.ValueOf(exported).Elem())
+	return true
+}
+
+func ownsFact(a *analysis.Analyzer, typ reflect.Type) bool {
+	for _, fact := range a.FactTypes {
+		if reflect.TypeOf(fact) == typ {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package driver_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyunsooda/paramguard/checker/driver"
+	"github.com/hyunsooda/paramguard/checker/passes"
+	"github.com/hyunsooda/paramguard/checker/report"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+// testdataConfig loads a package of the checker's testdata in GOPATH mode, as analysistest does
+func testdataConfig(t *testing.T, pkg string) (string, driver.Config) {
+	testdata, err := filepath.Abs(filepath.Join("..", "passes", "testdata"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testdata, driver.Config{
+		Dir: filepath.Join(testdata, "src", pkg),
+		Env: append(os.Environ(), "GOPATH="+testdata, "GO111MODULE=off", "GOPROXY=off"),
+	}
+}
+
+// The driver finds the same as the analysis framework, including the findings relying on the facts of the imports
+func TestRun(t *testing.T) {
+	passes.Init()
+	for _, pkg := range []string{"summary", "confirmed", "members"} {
+		testdata, config := testdataConfig(t, pkg)
+		want := analysistest.Run(t, testdata, passes.MainAnalyzer, pkg)[0].Result.([]*report.Finding)
+		findings, err := driver.Run(context.Background(), config, ".")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(findings) != len(want) {
+			t.Fatalf("%s: %d findings, want %d", pkg, len(findings), len(want))
+		}
+		found := make(map[string]bool)
+		for _, f := range findings {
+			found[f.Pos.String()+" "+f.Message()] = true
+		}
+		for _, f := range want {
+			if !found[f.Pos.String()+" "+f.Message()] {
+				t.Errorf("%s: missing %s: %s", pkg, f.Pos, f.Message())
+			}
+		}
+	}
+}
+
+func TestSARIF(t *testing.T) {
+	passes.Init()
+	testdata, config := testdataConfig(t, "confirmed")
+	findings, err := driver.Run(context.Background(), config, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := report.WriteSARIF(&buf, findings, testdata); err != nil {
+		t.Fatal(err)
+	}
+
+	var log struct {
+		Version string
+		Runs    []struct {
+			Results []struct {
+				RuleID    string
+				Level     string
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI       string
+							URIBaseID string
+						}
+						Region struct{ StartLine int }
+					}
+				}
+				PartialFingerprints map[string]string
+			}
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || len(log.Runs[0].Results) != len(findings) {
+		t.Fatalf("unexpected log: version %q, %d runs", log.Version, len(log.Runs))
+	}
+	fingerprints := make(map[string]bool)
+	for _, result := range log.Runs[0].Results {
+		location := result.Locations[0].PhysicalLocation
+		if result.RuleID == "" || location.ArtifactLocation.URIBaseID != "%SRCROOT%" || location.Region.StartLine == 0 {
+			t.Errorf("unlocated result: %+v", result)
+		}
+		fingerprint := result.PartialFingerprints["paramguardFingerprint/v1"]
+		if fingerprint == "" || fingerprints[fingerprint] {
+			t.Errorf("fingerprint %q is missing or not unique", fingerprint)
+		}
+		fingerprints[fingerprint] = true
+	}
+}
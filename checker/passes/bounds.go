@@ -0,0 +1,170 @@
+package passes
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/hyunsooda/paramguard/checker/common"
+	"github.com/hyunsooda/paramguard/checker/passtyps"
+)
+
+// forgetLenAbove drops the relations to the assigned variable `v`, e.g., `i` after `i++`
+func (s guardSet) forgetLenAbove(v types.Object) guardSet {
+	if v == nil {
+		return s
+	}
+	var m guardSet
+	for k, guard := range s {
+		if guard.LenAbove != v {
+			continue
+		}
+		if m == nil {
+			m = s.copy()
+		}
+		forgotten := *guard
+		forgotten.LenAbove, forgotten.LenMargin = nil, 0
+		m[k] = &forgotten
+	}
+	if m == nil {
+		return s
+	}
+	return m
+}
+
+func (s guardSet) copy() guardSet {
+	m := make(guardSet, len(s))
+	for k, guard := range s {
+		m[k] = guard
+	}
+	return m
+}
+
+// coversIndex reports whether the length proven by `guard` covers the index, or the slice bounds, of the use.
+// A nil check proves no length, as a non-nil slice may still be empty.
+func coversIndex(ctx passtyps.Context, usage *passtyps.ParamUsage, guard *passtyps.ParamUsage) bool {
+	if usage.Op != passtyps.OpSliceIndex {
+		return true
+	}
+	switch expr := usage.UseAt.(type) {
+	case *ast.IndexExpr:
+		return boundCovered(ctx, usage, guard, expr.Index, 1)
+	case *ast.SliceExpr:
+		for _, bound := range []ast.Expr{expr.Low, expr.High, expr.Max} {
+			if bound != nil && !boundCovered(ctx, usage, guard, bound, 0) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// boundCovered reports whether `len(x) >= bound + extra` follows from the guard on the used x,
+// where an index needs one more element than a slice bound
+func boundCovered(ctx passtyps.Context, param *passtyps.ParamUsage, guard *passtyps.ParamUsage, bound ast.Expr, extra int) bool {
+	info := ctx.Pass.TypesInfo
+	bound = unparen(bound)
+	if n, isConst := common.ConstInt(info, bound); isConst {
+		return int(n)+extra <= guard.MinLen
+	}
+	if v, offset, ok := offsetOf(ctx, bound); ok && guard.LenAbove != nil && v == guard.LenAbove {
+		return offset+extra <= guard.LenMargin // e.g., b[i] after `i < len(b)`, or b[k-1] after `k <= len(b)`
+	}
+	if isLenOf(ctx, bound, param) {
+		return extra == 0 // e.g., b[:len(b)]
+	}
+	// e.g., b[len(b)-1]
+	if binaryExpr, ok := bound.(*ast.BinaryExpr); ok && binaryExpr.Op == token.SUB && isLenOf(ctx, binaryExpr.X, param) {
+		if n, isConst := common.ConstInt(info, binaryExpr.Y); isConst {
+			return int(n) >= extra && int(n) <= guard.MinLen
+		}
+	}
+	return false
+}
+
+// offsetOf splits the bound `v`, `v+c` or `v-c` into the variable v and the constant offset
+func offsetOf(ctx passtyps.Context, bound ast.Expr) (types.Object, int, bool) {
+	info := ctx.Pass.TypesInfo
+	switch expr := unparen(bound).(type) {
+	case *ast.Ident:
+		return info.ObjectOf(expr), 0, true
+	case *ast.BinaryExpr:
+		ident, ok := unparen(expr.X).(*ast.Ident)
+		n, isConst := common.ConstInt(info, expr.Y)
+		if !ok || !isConst {
+			return nil, 0, false
+		}
+		switch expr.Op {
+		case token.ADD:
+			return info.ObjectOf(ident), int(n), true
+		case token.SUB:
+			return info.ObjectOf(ident), -int(n), true
+		}
+	}
+	return nil, 0, false
+}
+
+func isLenOf(ctx passtyps.Context, expr ast.Expr, param *passtyps.ParamUsage) bool {
+	callExpr, ok := unparen(expr).(*ast.CallExpr)
+	if !ok || builtinName(ctx, callExpr) != "len" || len(callExpr.Args) != 1 {
+		return false
+	}
+	return sameValue(paramValue(ctx, callExpr.Args[0]), param)
+}
+
+// isRangeKeyIndex reports whether the use indexes the parameter with the key ranging over it, e.g., `b[i]`
+// in `for i := range b`, which never goes out of range
+func (fg *flowGraph) isRangeKeyIndex(ctx passtyps.Context, usage *passtyps.ParamUsage) bool {
+	indexExpr, ok := usage.UseAt.(*ast.IndexExpr)
+	if !ok {
+		return false
+	}
+	ident, ok := unparen(indexExpr.Index).(*ast.Ident)
+	return ok && fg.rangeKeys[ctx.Pass.TypesInfo.ObjectOf(ident)] == guardID{param: usage.Param, context: usage.Context}
+}
+
+// rangeKeys maps the keys of `for i := range x` to the parameter or member x,
+// unless either of them is assigned in the body
+func rangeKeys(ctx passtyps.Context, body *ast.BlockStmt) map[types.Object]guardID {
+	info := ctx.Pass.TypesInfo
+	keys := make(map[types.Object]guardID)
+	assigned := make(map[types.Object]bool)
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch stmt := n.(type) {
+		case *ast.RangeStmt:
+			key, ok := stmt.Key.(*ast.Ident)
+			if !ok || stmt.Tok != token.DEFINE {
+				break
+			}
+			if v := paramValue(ctx, stmt.X); v != nil && common.IsSliceTyp(v.Param) {
+				keys[info.Defs[key]] = guardID{param: v.Param, context: v.Context}
+			}
+		case *ast.AssignStmt:
+			if stmt.Tok != token.DEFINE {
+				for _, lhs := range stmt.Lhs {
+					switch lhs := unparen(lhs).(type) {
+					case *ast.Ident:
+						assigned[info.ObjectOf(lhs)] = true
+					case *ast.SelectorExpr: // e.g., s.items = nil
+						assigned[info.ObjectOf(lhs.Sel)] = true
+					}
+				}
+			}
+		case *ast.IncDecStmt:
+			if ident, ok := unparen(stmt.X).(*ast.Ident); ok {
+				assigned[info.ObjectOf(ident)] = true
+			}
+		case *ast.UnaryExpr:
+			if ident, ok := unparen(stmt.X).(*ast.Ident); ok && stmt.Op == token.AND {
+				assigned[info.ObjectOf(ident)] = true
+			}
+		}
+		return true
+	})
+	for key, v := range keys {
+		if assigned[key] || assigned[v.param] || (v.context != nil && assigned[v.context]) {
+			delete(keys, key)
+		}
+	}
+	return keys
+}
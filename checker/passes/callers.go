@@ -0,0 +1,307 @@
+package passes
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+
+	"github.com/hyunsooda/paramguard/checker/common"
+	"github.com/hyunsooda/paramguard/checker/passtyps"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// callSites records how the arguments are passed to the statically called functions.
+// Unexported functions can only be called from the package itself, so all of their callers are known.
+type callSites struct {
+	sites   map[*types.Func][]callSite
+	callees map[*ast.Ident]bool // identifiers referring to a function as a callee
+}
+
+type callSite struct {
+	call *ast.CallExpr
+	args []argKind
+}
+
+type argKind int
+
+const (
+	argUnknown argKind = iota
+	argNonNil          // provably non-nil
+	argNil             // definitely nil
+)
+
+func newCallSites() *callSites {
+	return &callSites{
+		sites:   make(map[*types.Func][]callSite),
+		callees: make(map[*ast.Ident]bool),
+	}
+}
+
+// record classifies the arguments of `n` if it is a call, given the guards holding at the call
+func (cs *callSites) record(ctx passtyps.Context, n ast.Node, state guardSet) {
+	callExpr, ok := n.(*ast.CallExpr)
+	if !ok {
+		return
+	}
+	if ident := calleeIdent(callExpr.Fun); ident != nil {
+		cs.callees[ident] = true
+	}
+	fn := typeutil.StaticCallee(ctx.Pass.TypesInfo, callExpr)
+	if fn == nil {
+		return
+	}
+	args := make([]argKind, len(callExpr.Args))
+	for i, arg := range callExpr.Args {
+		if isNonNilArg(ctx, arg, state) {
+			args[i] = argNonNil
+		} else if isNilArg(ctx, arg, state) {
+			args[i] = argNil
+		}
+	}
+	cs.sites[fn.Origin()] = append(cs.sites[fn.Origin()], callSite{callExpr, args})
+}
+
+// recordAll classifies the calls in `root` without any guard, e.g., in the package-level variable initializers
+func (cs *callSites) recordAll(ctx passtyps.Context, root ast.Node) {
+	ast.Inspect(root, func(n ast.Node) bool {
+		cs.record(ctx, n, guardSet{})
+		return true
+	})
+}
+
+func calleeIdent(fun ast.Expr) *ast.Ident {
+	switch expr := fun.(type) {
+	case *ast.ParenExpr:
+		return calleeIdent(expr.X)
+	case *ast.IndexExpr: // e.g., f[int](p)
+		return calleeIdent(expr.X)
+	case *ast.IndexListExpr:
+		return calleeIdent(expr.X)
+	case *ast.SelectorExpr:
+		return expr.Sel
+	case *ast.Ident:
+		return expr
+	}
+	return nil
+}
+
+// isNonNilArg reports whether the argument is non-nil by construction (e.g., `&x`, `new(T)`, `T{...}`),
+// or guarded in the caller
+func isNonNilArg(ctx passtyps.Context, arg ast.Expr, state guardSet) bool {
+	info := ctx.Pass.TypesInfo
+	switch expr := arg.(type) {
+	case *ast.ParenExpr:
+		return isNonNilArg(ctx, expr.X, state)
+	case *ast.UnaryExpr:
+		return expr.Op == token.AND
+	case *ast.FuncLit:
+		return true
+	case *ast.CompositeLit:
+		if _, ok := common.CoreTyp(info.TypeOf(expr)).(*types.Slice); ok {
+			return len(expr.Elts) > 0 // an empty slice literal is still unsafe to index
+		}
+		return true
+	case *ast.CallExpr:
+		fnIdent, ok := expr.Fun.(*ast.Ident)
+		if !ok {
+			return false
+		}
+		if _, ok := info.Uses[fnIdent].(*types.Builtin); !ok {
+			return false
+		}
+		switch fnIdent.Name {
+		case "new":
+			return true
+		case "make":
+			if _, ok := common.CoreTyp(info.TypeOf(expr)).(*types.Slice); ok {
+				if len(expr.Args) < 2 {
+					return false
+				}
+				n, isConst := common.ConstInt(info, expr.Args[1])
+				return isConst && n > 0
+			}
+			return true
+		}
+		return false
+	case *ast.Ident, *ast.SelectorExpr:
+		if fn, ok := info.ObjectOf(common.Cast2Ident(arg)).(*types.Func); ok && fn != nil {
+			return true // function value
+		}
+		root, path := common.MemberPath(info, arg)
+		if _, ok := root.(*ast.Ident); !ok {
+			return false
+		}
+		v := common.IsTargetedParam(ctx, root)
+		if v == nil {
+			return false
+		}
+		guarded := passtyps.NewParamUsage(v, nil, nil, v.Pos())
+		if len(path) > 0 {
+			guarded.Param = path[len(path)-1]
+			guarded.Context = v
+		}
+		return state[guardKey(guarded)] != nil
+	}
+	return false
+}
+
+// isNilArg reports whether the argument is nil (e.g., `nil`, `(*T)(nil)`, `var p *T`) or an empty slice literal
+func isNilArg(ctx passtyps.Context, arg ast.Expr, state guardSet) bool {
+	if compositeLit, ok := arg.(*ast.CompositeLit); ok {
+		_, isSlice := common.CoreTyp(ctx.Pass.TypesInfo.TypeOf(compositeLit)).(*types.Slice)
+		return isSlice && len(compositeLit.Elts) == 0
+	}
+	return isNilExpr(ctx, arg, state)
+}
+
+// filterByCallers drops or downgrades the uses of the parameters that every caller provably passes non-nil.
+// Exported functions stay strict since their callers are unknown.
+func filterByCallers(pass *analysis.Pass, config *passtyps.Config, cs *callSites, unsanitized []*passtyps.ParamUsage) []*passtyps.ParamUsage {
+	if config == nil || (config.Callers != passtyps.CALLERS_DROP && config.Callers != passtyps.CALLERS_DOWNGRADE) {
+		return unsanitized
+	}
+	cs.recordPkgVars(pass)
+
+	// Functions referred to other than by a call (e.g., passed as a callback) have unknown callers
+	escaped := make(map[*types.Func]bool)
+	for ident, obj := range pass.TypesInfo.Uses {
+		if fn, ok := obj.(*types.Func); ok && !cs.callees[ident] {
+			escaped[fn.Origin()] = true
+		}
+	}
+	ifaceMethods := interfaceMethodNames(pass)
+
+	var filtered []*passtyps.ParamUsage
+	for _, use := range unsanitized {
+		if n, ok := nonNilCallers(cs, use, escaped, ifaceMethods); ok {
+			if config.Callers == passtyps.CALLERS_DROP {
+				continue
+			}
+			use.NonNilCallers = n
+		}
+		filtered = append(filtered, use)
+	}
+	return filtered
+}
+
+// nonNilCallers returns the number of call sites if all of them pass a non-nil argument to the used parameter
+func nonNilCallers(cs *callSites, use *passtyps.ParamUsage, escaped map[*types.Func]bool, ifaceMethods map[string]bool) (int, bool) {
+	fn := use.Fn.Origin()
+	if use.Context != nil || fn.Exported() || escaped[fn] {
+		return 0, false
+	}
+	sig := fn.Type().(*types.Signature)
+	if sig.Recv() != nil && ifaceMethods[fn.Name()] {
+		return 0, false // may be called through an interface
+	}
+	index, ok := paramIndex(sig, use.Param)
+	if !ok || index < 0 || (sig.Variadic() && index == sig.Params().Len()-1) {
+		return 0, false
+	}
+	sites := cs.sites[fn]
+	if len(sites) == 0 {
+		return 0, false
+	}
+	for _, site := range sites {
+		if index >= len(site.args) || site.args[index] != argNonNil {
+			return 0, false
+		}
+	}
+	return len(sites), true
+}
+
+// confirmNilCalls finds the calls passing nil to a parameter that the callee uses without a guard.
+// The callees declared in the other packages are looked up in their summaries.
+func confirmNilCalls(pass *analysis.Pass, cs *callSites, uses []*passtyps.ParamUsage, summaries passtyps.GuardSummaries) []*passtyps.ConfirmedCall {
+	// first unguarded use per parameter of the functions in the package
+	pkgUses := make(map[*types.Func]map[int]*passtyps.ParamUsage)
+	for _, use := range uses {
+		if use.Context != nil {
+			continue
+		}
+		fn := use.Fn.Origin()
+		index, ok := paramIndex(fn.Type().(*types.Signature), use.Param)
+		if !ok || index < 0 {
+			continue
+		}
+		if pkgUses[fn] == nil {
+			pkgUses[fn] = make(map[int]*passtyps.ParamUsage)
+		}
+		if prev, ok := pkgUses[fn][index]; !ok || use.UseAt.Pos() < prev.UseAt.Pos() {
+			pkgUses[fn][index] = use
+		}
+	}
+
+	var confirmed []*passtyps.ConfirmedCall
+	for fn, sites := range cs.sites {
+		sig := fn.Type().(*types.Signature)
+		for _, site := range sites {
+			for i, kind := range site.args {
+				if kind != argNil || (sig.Variadic() && i >= sig.Params().Len()-1) {
+					continue
+				}
+				call := &passtyps.ConfirmedCall{
+					Callee: fn,
+					CallAt: site.call,
+					Arg:    site.call.Args[i],
+				}
+				if fn.Pkg() == pass.Pkg {
+					use, ok := pkgUses[fn][i]
+					if !ok {
+						continue
+					}
+					call.Param, call.Use, call.Op = use.Param.Name(), use, use.Op
+				} else {
+					param, ok := unguardedParam(summaries[fn], i)
+					if !ok {
+						continue
+					}
+					call.Param, call.UseLoc, call.Op = param.Name, param.UseAt, param.Op
+				}
+				confirmed = append(confirmed, call)
+			}
+		}
+	}
+	sort.Slice(confirmed, func(i, j int) bool {
+		return confirmed[i].Arg.Pos() < confirmed[j].Arg.Pos()
+	})
+	return confirmed
+}
+
+func unguardedParam(summary *passtyps.GuardFact, index int) (passtyps.UnguardedParam, bool) {
+	if summary == nil {
+		return passtyps.UnguardedParam{}, false
+	}
+	for _, param := range summary.Unguarded {
+		if param.Index == index {
+			return param, true
+		}
+	}
+	return passtyps.UnguardedParam{}, false
+}
+
+// recordPkgVars classifies the calls in package-level variable initializers
+func (cs *callSites) recordPkgVars(pass *analysis.Pass) {
+	ctx := passtyps.NewContext(pass, nil, nil)
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.VAR {
+				cs.recordAll(ctx, genDecl)
+			}
+		}
+	}
+}
+
+func interfaceMethodNames(pass *analysis.Pass) map[string]bool {
+	names := make(map[string]bool)
+	for _, tv := range pass.TypesInfo.Types {
+		if iface, ok := tv.Type.Underlying().(*types.Interface); ok {
+			for i := 0; i < iface.NumMethods(); i++ {
+				names[iface.Method(i).Name()] = true
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,21 @@
+package passes
+
+import (
+	"reflect"
+
+	"github.com/hyunsooda/paramguard/checker/passtyps"
+	"golang.org/x/tools/go/analysis"
+)
+
+// ConfigCollector provides the configuration of the checker as a *passtyps.Config, read from the flags.
+// A driver configuring each of its runs provides the result instead, see driver.Run.
+var ConfigCollector = &analysis.Analyzer{
+	Doc:        "Assistant pass for ParamGuard analyzer",
+	Name:       "configcollector",
+	Run:        runConfigCollector,
+	ResultType: reflect.TypeOf(new(passtyps.Config)),
+}
+
+func runConfigCollector(pass *analysis.Pass) (interface{}, error) {
+	return passtyps.ParseConfig(pass), nil
+}
@@ -0,0 +1,124 @@
+package passes
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/hyunsooda/paramguard/checker/common"
+	"github.com/hyunsooda/paramguard/checker/passtyps"
+)
+
+// elemTyp returns the element type of a slice, array or map, nil for the other types
+func elemTyp(typ types.Type) types.Type {
+	switch t := common.CoreTyp(typ).(type) {
+	case *types.Slice:
+		return t.Elem()
+	case *types.Array:
+		return t.Elem()
+	case *types.Map:
+		return t.Elem()
+	case *types.Pointer: // e.g., p[i] of p *[4]*T
+		if array, ok := t.Elem().Underlying().(*types.Array); ok {
+			return array.Elem()
+		}
+	}
+	return nil
+}
+
+// hasNilableElems reports whether the elements of the parameter or member are checked in the element mode
+func hasNilableElems(ctx passtyps.Context, container *passtyps.ParamUsage) bool {
+	if ctx.Elements == nil || container == nil {
+		return false
+	}
+	elem := elemTyp(container.Param.Type())
+	return elem != nil && common.IsNilableTyp(elem)
+}
+
+// elementValue returns the element of a parameter, or of its member, indexed by `indexExpr` in the element mode,
+// e.g., `items[i]` or `s.conns[k]`. The elements indexed alike share their guards.
+func elementValue(ctx passtyps.Context, indexExpr *ast.IndexExpr) *passtyps.ParamUsage {
+	container := paramValue(ctx, indexExpr.X)
+	if !hasNilableElems(ctx, container) {
+		return nil
+	}
+	key := passtyps.ElementKey{
+		Container: container.Param,
+		Context:   container.Context,
+		Index:     types.ExprString(indexExpr.Index),
+	}
+	element, ok := ctx.Elements[key]
+	if !ok {
+		element = &passtyps.Element{
+			Var:   types.NewVar(container.DeclaredAt, ctx.Pass.Pkg, types.ExprString(indexExpr), elemTyp(container.Param.Type())),
+			Index: indexVars(ctx, indexExpr.Index),
+		}
+		ctx.Elements[key] = element
+	}
+	return passtyps.NewParamUsage(element.Var, nil, nil, container.DeclaredAt)
+}
+
+func indexVars(ctx passtyps.Context, index ast.Expr) []types.Object {
+	var vars []types.Object
+	ast.Inspect(index, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok {
+			if v, ok := ctx.Pass.TypesInfo.Uses[ident].(*types.Var); ok {
+				vars = append(vars, v)
+			}
+		}
+		return true
+	})
+	return vars
+}
+
+// elementValues returns the locals holding the elements of the parameters in the element mode:
+// the values of `for _, v := range items` and of `v := m[k]` or `v, ok := m[k]`
+func elementValues(ctx passtyps.Context, body *ast.BlockStmt) []types.Object {
+	if ctx.Elements == nil {
+		return nil
+	}
+	info := ctx.Pass.TypesInfo
+	ctx.Params = append([]types.Object{}, ctx.Params...)
+	var values []types.Object
+	track := func(value ast.Expr, container ast.Expr) {
+		ident, ok := value.(*ast.Ident)
+		if !ok || ident.Name == "_" || !hasNilableElems(ctx, paramValue(ctx, container)) {
+			return
+		}
+		if obj := info.Defs[ident]; obj != nil {
+			values = append(values, obj)
+			ctx.Params = append(ctx.Params, obj) // e.g., the rows of a matrix ranged over in turn
+		}
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch stmt := n.(type) {
+		case *ast.FuncLit:
+			return false // analyzed on its own
+		case *ast.RangeStmt:
+			if stmt.Tok == token.DEFINE && stmt.Value != nil {
+				track(stmt.Value, stmt.X)
+			}
+		case *ast.AssignStmt:
+			if stmt.Tok == token.DEFINE && len(stmt.Rhs) == 1 && (len(stmt.Lhs) == 1 || len(stmt.Lhs) == 2) {
+				if indexExpr, ok := unparen(stmt.Rhs[0]).(*ast.IndexExpr); ok {
+					track(stmt.Lhs[0], indexExpr.X)
+				}
+			}
+		}
+		return true
+	})
+	return values
+}
+
+// killElements drops the facts on the elements of `v`, or indexed by `v`, e.g., `items[i]` after `i++`
+func (s guardSet) killElements(ctx passtyps.Context, v types.Object) guardSet {
+	if v == nil {
+		return s
+	}
+	for key, element := range ctx.Elements {
+		if key.Container == v || key.Context == v || findObj(element.Index, v) {
+			s = s.kill(element.Var, nil)
+		}
+	}
+	return s
+}
@@ -0,0 +1,1078 @@
+package passes
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+
+	"github.com/hyunsooda/paramguard/checker/common"
+	"github.com/hyunsooda/paramguard/checker/passtyps"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/cfg"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// guardSet maps a tracked parameter (or member) to the guard proving it is non-nil.
+// It also holds the facts proving a variable is nil, keyed apart from the guards.
+type guardSet map[guardID]*passtyps.ParamUsage
+
+// guardID identifies the variable, or the member of a parameter, a guard is about
+type guardID struct {
+	param   types.Object
+	context types.Object
+	isNil   bool
+}
+
+// branchCond is the condition deciding which successor of a conditional block is taken.
+// Succs[0] is taken when the condition holds, Succs[1] otherwise.
+type branchCond struct {
+	cond      ast.Expr            // boolean condition of if, for and switch case
+	typSwitch *ast.TypeSwitchStmt // type switch the case type belongs to
+	caseTyp   ast.Expr
+	errCalls  errCalls // calls the error variables checked by `cond` were assigned from
+}
+
+type errCalls = map[types.Object]*ast.CallExpr
+
+type flowGraph struct {
+	g         *cfg.CFG
+	conds     map[*cfg.Block]branchCond
+	exits     map[*cfg.Block]int // index of the node terminating the block
+	summaries func(*types.Func) *passtyps.GuardFact
+	escaped   map[types.Object]bool          // variables modified behind the flow, by their address or from a closure
+	entry     guardSet                       // guards holding when the function starts, e.g., captured by a closure
+	ranged    map[ast.Node]op                // expressions ranged over, whose node the CFG keeps alone
+	selectOps map[ast.Node]bool              // sends and receives of select cases, where a nil channel disables the case
+	rangeKeys map[types.Object]guardID       // keys of `for i := range x`, always in the range of the parameter or member x
+	commaOk   map[types.Object]commaOkAssert // `ok` variables of comma-ok type assertions and map lookups
+	locals    trackedLocals                  // locals checked alongside the parameters
+	sig       *types.Signature               // signature of the function, whose interface results may hold a typed nil
+}
+
+// commaOkAssert is a type assertion of the form `v, ok := x.(T)`, or a map lookup `v, ok := m[k]`
+type commaOkAssert struct {
+	expr   *ast.TypeAssertExpr
+	lookup *ast.IndexExpr
+	value  *ast.Ident // v, nil if blank
+}
+
+// Methods of testing.TB stopping the test; the CFG cannot see through the interface dispatch
+var tbExits = map[string]bool{
+	"Fatal":   true,
+	"Fatalf":  true,
+	"FailNow": true,
+	"Skip":    true,
+	"Skipf":   true,
+	"SkipNow": true,
+}
+
+func guardKey(usage *passtyps.ParamUsage) guardID {
+	return guardID{usage.Param, usage.Context, usage.IsNil}
+}
+
+func (s guardSet) with(guards []*passtyps.ParamUsage) guardSet {
+	if len(guards) == 0 {
+		return s
+	}
+	m := make(guardSet, len(s)+len(guards))
+	for k, guard := range s {
+		m[k] = guard
+	}
+	for _, guard := range guards {
+		k := guardKey(guard)
+		if prev, ok := m[k]; ok {
+			guard = stronger(prev, guard)
+		}
+		m[k] = guard
+	}
+	return m
+}
+
+func (s guardSet) without(key guardID) guardSet {
+	if _, ok := s[key]; !ok {
+		return s
+	}
+	m := make(guardSet, len(s))
+	for k, guard := range s {
+		if k != key {
+			m[k] = guard
+		}
+	}
+	return m
+}
+
+// meet keeps the guards holding on both of the incoming paths
+func (s guardSet) meet(other guardSet) guardSet {
+	m := make(guardSet)
+	for k, guard := range s {
+		if otherGuard, ok := other[k]; ok {
+			m[k] = weaker(guard, otherGuard)
+		}
+	}
+	return m
+}
+
+func (s guardSet) equal(other guardSet) bool {
+	if len(s) != len(other) {
+		return false
+	}
+	for k, guard := range s {
+		otherGuard, ok := other[k]
+		if !ok || guard.MinLen != otherGuard.MinLen || guard.LenAbove != otherGuard.LenAbove || guard.LenMargin != otherGuard.LenMargin ||
+			guard.Reassigned != otherGuard.Reassigned || !sameTyp(guard.AssertedTyp, otherGuard.AssertedTyp) {
+			return false
+		}
+	}
+	return true
+}
+
+// stronger merges two guards on the same value, both holding
+func stronger(prev, guard *passtyps.ParamUsage) *passtyps.ParamUsage {
+	lenMerged := isLenGuard(prev) && isLenGuard(guard) && (prev.MinLen > guard.MinLen || guard.LenAbove == nil ||
+		guard.LenAbove == prev.LenAbove && prev.LenMargin > guard.LenMargin)
+	typMerged := guard.AssertedTyp == nil && prev.AssertedTyp != nil
+	reassigned := prev.Reassigned && !guard.Reassigned // a later check is still on the new value
+	if guard.IsNil || prev.IsNil || (!lenMerged && !typMerged && !reassigned) {
+		return guard
+	}
+	merged := *guard
+	merged.Reassigned = merged.Reassigned || reassigned
+	if lenMerged {
+		if prev.MinLen > merged.MinLen {
+			merged.MinLen = prev.MinLen
+		}
+		if merged.LenAbove == nil {
+			merged.LenAbove, merged.LenMargin = prev.LenAbove, prev.LenMargin
+		} else if merged.LenAbove == prev.LenAbove && prev.LenMargin > merged.LenMargin {
+			merged.LenMargin = prev.LenMargin
+		}
+	}
+	if typMerged {
+		merged.AssertedTyp = prev.AssertedTyp
+	}
+	return &merged
+}
+
+// weaker keeps what both guards on the same value prove.
+// A nil check does not bound the length, so it is weaker than nothing but a length guard.
+func weaker(guard, other *passtyps.ParamUsage) *passtyps.ParamUsage {
+	if !isLenGuard(guard) && isLenGuard(other) {
+		guard, other = other, guard
+	}
+	lenWeakened := isLenGuard(other) && (other.MinLen < guard.MinLen || other.LenAbove != guard.LenAbove ||
+		other.LenMargin < guard.LenMargin)
+	typWeakened := guard.AssertedTyp != nil && !sameTyp(guard.AssertedTyp, other.AssertedTyp)
+	reassigned := other.Reassigned && !guard.Reassigned
+	if !lenWeakened && !typWeakened && !reassigned {
+		return guard
+	}
+	merged := *guard
+	merged.Reassigned = merged.Reassigned || reassigned
+	if lenWeakened {
+		if other.MinLen < merged.MinLen {
+			merged.MinLen = other.MinLen
+		}
+		if other.LenAbove != merged.LenAbove {
+			merged.LenAbove, merged.LenMargin = nil, 0
+		} else if other.LenMargin < merged.LenMargin {
+			merged.LenMargin = other.LenMargin
+		}
+	}
+	if typWeakened {
+		merged.AssertedTyp = nil
+	}
+	return &merged
+}
+
+// isLenGuard reports whether the guard bounds the length, e.g., `len(b) > 0` or `len(b) >= k`
+func isLenGuard(guard *passtyps.ParamUsage) bool {
+	return guard.MinLen > 0 || guard.LenAbove != nil
+}
+
+func sameTyp(t1, t2 types.Type) bool {
+	if t1 == nil || t2 == nil {
+		return t1 == t2
+	}
+	return types.Identical(t1, t2)
+}
+
+func (fg *flowGraph) condGuards(ctx passtyps.Context, c branchCond, branch bool) []*passtyps.ParamUsage {
+	if c.typSwitch != nil {
+		return runTypSwitchStmt(ctx, c.typSwitch, c.caseTyp, branch)
+	}
+	return fg.branchGuards(ctx, c.cond, branch, c.errCalls)
+}
+
+// newFlowGraph attaches the branch conditions to the conditional blocks of `g`.
+// The CFG only keeps conditions of if, for and tagless switch statements as the last node of a block,
+// so the case values of tagged switches and the case types of type switches are recovered from the syntax.
+func newFlowGraph(ctx passtyps.Context, g *cfg.CFG, body *ast.BlockStmt, summaries func(*types.Func) *passtyps.GuardFact) *flowGraph {
+	fg := &flowGraph{
+		g:         g,
+		conds:     make(map[*cfg.Block]branchCond),
+		exits:     make(map[*cfg.Block]int),
+		summaries: summaries,
+		escaped:   escapedVars(ctx, body),
+		ranged:    make(map[ast.Node]op),
+		selectOps: make(map[ast.Node]bool),
+		rangeKeys: rangeKeys(ctx, body),
+		commaOk:   commaOkAsserts(ctx.Pass, body),
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch stmt := n.(type) {
+		case *ast.RangeStmt:
+			fg.ranged[stmt.X] = opRange
+			if stmt.Value != nil {
+				fg.ranged[stmt.X] = opRangeValue
+			}
+		case *ast.CommClause:
+			switch comm := stmt.Comm.(type) {
+			case *ast.SendStmt:
+				fg.selectOps[comm] = true
+			case *ast.ExprStmt: // case <-ch:
+				fg.selectOps[unparen(comm.X)] = true
+			case *ast.AssignStmt: // case v := <-ch:
+				for _, rhs := range comm.Rhs {
+					fg.selectOps[unparen(rhs)] = true
+				}
+			}
+		}
+		return true
+	})
+	lastNodes := make(map[ast.Node]*cfg.Block)
+	for _, blk := range g.Blocks {
+		if len(blk.Nodes) > 0 && len(blk.Succs) == 2 {
+			lastNodes[blk.Nodes[len(blk.Nodes)-1]] = blk
+		}
+		for i, n := range blk.Nodes {
+			if neverReturns(ctx, n) {
+				fg.exits[blk] = i
+				break
+			}
+		}
+	}
+
+	tags := make(map[ast.Expr]ast.Expr)
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch stmt := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.SwitchStmt:
+			if stmt.Tag != nil {
+				for _, clause := range stmt.Body.List {
+					for _, caseExpr := range clause.(*ast.CaseClause).List {
+						tags[caseExpr] = stmt.Tag
+					}
+				}
+			}
+		case *ast.TypeSwitchStmt:
+			// Each case type is tested in its own block, chained through the false branches
+			blk := lastNodes[stmt.Assign]
+			for _, clause := range stmt.Body.List {
+				for _, caseTyp := range clause.(*ast.CaseClause).List {
+					if blk == nil || len(blk.Succs) != 2 {
+						return true
+					}
+					fg.conds[blk] = branchCond{typSwitch: stmt, caseTyp: caseTyp}
+					blk = blk.Succs[1]
+				}
+			}
+		}
+		return true
+	})
+
+	for n, blk := range lastNodes {
+		if cond, ok := n.(ast.Expr); ok {
+			if tag, ok := tags[cond]; ok {
+				// `switch tag { case cond: }` branches on `tag == cond`
+				cond = &ast.BinaryExpr{X: tag, OpPos: cond.Pos(), Op: token.EQL, Y: cond}
+			}
+			fg.conds[blk] = branchCond{cond: cond, errCalls: blockErrCalls(ctx, blk)}
+		}
+	}
+	return fg
+}
+
+// blockErrCalls collects the calls the error variables were last assigned from within `blk`,
+// e.g., `if err := validate(p); err != nil` or `err = validate(p)` right before `if err != nil`
+func blockErrCalls(ctx passtyps.Context, blk *cfg.Block) errCalls {
+	calls := make(errCalls)
+	for _, n := range blk.Nodes {
+		assignStmt, ok := n.(*ast.AssignStmt)
+		if !ok {
+			continue
+		}
+		for _, lhs := range assignStmt.Lhs {
+			if ident, ok := lhs.(*ast.Ident); ok {
+				if obj := ctx.Pass.TypesInfo.ObjectOf(ident); obj != nil {
+					delete(calls, obj)
+				}
+			}
+		}
+		if len(assignStmt.Rhs) != 1 {
+			continue
+		}
+		callExpr, ok := assignStmt.Rhs[0].(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		// the error is the last result, e.g., `v, err := parse(p)`
+		if ident, ok := assignStmt.Lhs[len(assignStmt.Lhs)-1].(*ast.Ident); ok {
+			if obj := ctx.Pass.TypesInfo.ObjectOf(ident); obj != nil && common.IsErrorTyp(obj.Type()) {
+				calls[obj] = callExpr
+			}
+		}
+	}
+	return calls
+}
+
+// solve computes the guards holding at the entry of each reachable block.
+// A guard holds at a block only if it holds on every path reaching the block.
+func (fg *flowGraph) solve(ctx passtyps.Context) map[*cfg.Block]guardSet {
+	in := make(map[*cfg.Block]guardSet)
+	if len(fg.g.Blocks) == 0 {
+		return in
+	}
+	entry := fg.g.Blocks[0]
+	in[entry] = guardSet{}
+	if fg.entry != nil {
+		in[entry] = fg.entry
+	}
+	worklist := []*cfg.Block{entry}
+	for len(worklist) > 0 {
+		blk := worklist[0]
+		worklist = worklist[1:]
+
+		if _, ok := fg.exits[blk]; ok {
+			continue
+		}
+		out := fg.walk(ctx, blk, in[blk], nil)
+		for i, succ := range blk.Succs {
+			state := out
+			if cond, ok := fg.conds[blk]; ok {
+				state = out.with(fg.condGuards(ctx, cond, i == 0))
+			}
+			prev, visited := in[succ]
+			if visited {
+				state = prev.meet(state)
+			}
+			if !visited || !state.equal(prev) {
+				in[succ] = state
+				worklist = append(worklist, succ)
+			}
+		}
+	}
+	return in
+}
+
+// walk visits the nodes of `blk` with the guards holding before each of them
+// and returns the guards holding at the end of the block
+func (fg *flowGraph) walk(ctx passtyps.Context, blk *cfg.Block, state guardSet, visit func(ast.Node, guardSet)) guardSet {
+	for i, n := range blk.Nodes {
+		if visit != nil {
+			visit(n, state)
+		}
+		if exit, ok := fg.exits[blk]; ok && exit == i {
+			break
+		}
+		state = state.with(fg.callGuards(ctx, n))
+		state = fg.assign(ctx, n, state)
+	}
+	return state
+}
+
+// assign updates the facts of the variables assigned by `n`: a parameter (or its member) follows its latest value,
+// e.g., `if cfg == nil { cfg = defaultConfig() }` guards `cfg` whereas `p = lookup(k)` drops the guard on `p`,
+// and a local is known to be nil after `var p *T` or `p = nil`
+func (fg *flowGraph) assign(ctx passtyps.Context, n ast.Node, state guardSet) guardSet {
+	var lhs, rhs []ast.Expr
+	zero := false
+	switch stmt := n.(type) {
+	case *ast.AssignStmt:
+		lhs = stmt.Lhs
+		if stmt.Tok == token.ASSIGN || stmt.Tok == token.DEFINE {
+			rhs = stmt.Rhs
+		}
+	case *ast.ValueSpec:
+		for _, name := range stmt.Names {
+			lhs = append(lhs, name)
+		}
+		rhs = stmt.Values
+		zero = len(stmt.Values) == 0
+	case *ast.IncDecStmt:
+		lhs = []ast.Expr{stmt.X}
+	case *ast.Ident: // key and value of a range statement
+		lhs = []ast.Expr{stmt}
+	default:
+		return state
+	}
+	for _, expr := range lhs {
+		if ident, ok := unparen(expr).(*ast.Ident); ok {
+			obj := ctx.Pass.TypesInfo.ObjectOf(ident)
+			state = state.forgetLenAbove(obj).killElements(ctx, obj)
+			if v := common.IsTargetedParam(ctx, ident); v != nil {
+				state = state.killElements(ctx, v)
+			}
+		} else if selectorExpr, ok := unparen(expr).(*ast.SelectorExpr); ok {
+			state = state.killElements(ctx, ctx.Pass.TypesInfo.ObjectOf(selectorExpr.Sel))
+		}
+	}
+
+	before := state
+	for i, expr := range lhs {
+		var value ast.Expr
+		if len(rhs) == len(lhs) {
+			value = rhs[i]
+		}
+		if v, path := paramTarget(ctx, expr); v != nil {
+			state = state.kill(v, path)
+			if value == nil && !zero {
+				continue
+			}
+			usage := passtyps.NewParamUsage(v, n, nil, v.Pos())
+			if len(path) > 0 {
+				usage.Param = path[len(path)-1]
+				usage.Context = v
+				usage.Path = path
+			} else {
+				usage.Reassigned = true
+			}
+			if zero { // e.g., `var e *MyErr` tracked as a parameter
+				usage.IsNil = true
+				state = state.with([]*passtyps.ParamUsage{usage})
+			} else if fg.isNonNilValue(ctx, value, before) {
+				usage.MinLen = appendedLen(ctx, value)
+				state = state.with([]*passtyps.ParamUsage{usage})
+			} else if isNilExpr(ctx, value, before) {
+				usage.IsNil = true
+				state = state.with([]*passtyps.ParamUsage{usage})
+			}
+			continue
+		}
+
+		ident, ok := expr.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		obj := ctx.Pass.TypesInfo.ObjectOf(ident)
+		if obj == nil {
+			continue
+		}
+		nilFact := passtyps.NewParamUsage(obj, n, nil, obj.Pos())
+		nilFact.IsNil = true
+		state = state.without(guardKey(nilFact))
+		if fg.escaped[obj] || !common.IsNilableTyp(obj.Type()) {
+			continue
+		}
+		if zero || (value != nil && isNilExpr(ctx, value, before)) {
+			state = state.with([]*passtyps.ParamUsage{nilFact})
+		}
+	}
+	return state
+}
+
+// paramTarget returns the parameter, and the member path, assigned by `lhs`, e.g., `p` or `p.a.b`,
+// or the element assigned in the element mode, e.g., `items[i]`
+func paramTarget(ctx passtyps.Context, lhs ast.Expr) (types.Object, []types.Object) {
+	if indexExpr, ok := unparen(lhs).(*ast.IndexExpr); ok {
+		if element := elementValue(ctx, indexExpr); element != nil {
+			return element.Param, nil
+		}
+		return nil, nil
+	}
+	root, path := common.MemberPath(ctx.Pass.TypesInfo, unparen(lhs))
+	if _, ok := root.(*ast.Ident); !ok {
+		return nil, nil
+	}
+	for _, member := range path {
+		if _, ok := member.(*types.Var); !ok {
+			return nil, nil
+		}
+	}
+	return common.IsTargetedParam(ctx, root), path
+}
+
+// kill drops the facts on the parameter `v`, or on its member at `path`, and on the members below it
+func (s guardSet) kill(v types.Object, path []types.Object) guardSet {
+	m := make(guardSet, len(s))
+	for k, guard := range s {
+		if !coveredBy(guard, v, path) {
+			m[k] = guard
+		}
+	}
+	return m
+}
+
+func coveredBy(guard *passtyps.ParamUsage, v types.Object, path []types.Object) bool {
+	if len(path) == 0 {
+		return guard.Param == v || guard.Context == v
+	}
+	if guard.Context != v {
+		return false
+	}
+	if len(guard.Path) == 0 {
+		return guard.Param == path[len(path)-1]
+	}
+	if len(guard.Path) < len(path) {
+		return false
+	}
+	for i, member := range path {
+		if guard.Path[i] != member {
+			return false
+		}
+	}
+	return true
+}
+
+// isNonNilValue reports whether the assigned value is non-nil, including `append(s, x)`
+// and the calls to the functions proven to return non-nil
+func (fg *flowGraph) isNonNilValue(ctx passtyps.Context, expr ast.Expr, state guardSet) bool {
+	if isNonNilArg(ctx, expr, state) {
+		return true
+	}
+	callExpr, ok := unparen(expr).(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	if fnIdent, ok := callExpr.Fun.(*ast.Ident); ok {
+		if _, ok := ctx.Pass.TypesInfo.Uses[fnIdent].(*types.Builtin); ok {
+			// e.g., append(opts, opt) is non-empty, but append(opts, others...) may not be
+			return fnIdent.Name == "append" && len(callExpr.Args) > 1 && !callExpr.Ellipsis.IsValid()
+		}
+	}
+	if _, summary := fg.summary(ctx, callExpr); summary != nil {
+		for _, index := range summary.NonNilResults {
+			if index == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// appendedLen returns the minimum length of the appended slice, e.g., 2 for `append(s, a, b)`
+func appendedLen(ctx passtyps.Context, expr ast.Expr) int {
+	callExpr, ok := unparen(expr).(*ast.CallExpr)
+	if !ok || builtinName(ctx, callExpr) != "append" || callExpr.Ellipsis.IsValid() {
+		return 0
+	}
+	return len(callExpr.Args) - 1
+}
+
+// isNilExpr reports whether `expr` is definitely nil: `nil`, `(*T)(nil)`, or a variable known to be nil in `state`
+func isNilExpr(ctx passtyps.Context, expr ast.Expr, state guardSet) bool {
+	info := ctx.Pass.TypesInfo
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return isNilExpr(ctx, e.X, state)
+	case *ast.CallExpr: // conversion, e.g., (*T)(nil)
+		if tv, ok := info.Types[e.Fun]; ok && tv.IsType() && len(e.Args) == 1 {
+			return isNilExpr(ctx, e.Args[0], state)
+		}
+	case *ast.Ident, *ast.SelectorExpr:
+		if ident, ok := e.(*ast.Ident); ok {
+			if _, ok := info.Uses[ident].(*types.Nil); ok {
+				return true
+			}
+		}
+		root, path := common.MemberPath(info, e)
+		rootIdent, ok := root.(*ast.Ident)
+		if !ok || state == nil {
+			return false
+		}
+		obj := info.ObjectOf(rootIdent)
+		if v := common.IsTargetedParam(ctx, rootIdent); v != nil {
+			obj = v
+		}
+		if obj == nil {
+			return false
+		}
+		nilFact := passtyps.NewParamUsage(obj, nil, nil, obj.Pos())
+		nilFact.IsNil = true
+		if len(path) > 0 {
+			nilFact.Param = path[len(path)-1]
+			nilFact.Context = obj
+		}
+		return state[guardKey(nilFact)] != nil
+	}
+	return false
+}
+
+// escapedVars collects the variables whose address is taken or which are assigned in a closure
+func escapedVars(ctx passtyps.Context, body *ast.BlockStmt) map[types.Object]bool {
+	escaped := make(map[types.Object]bool)
+	var inspectFn func(n ast.Node, inClosure bool)
+	inspectFn = func(root ast.Node, inClosure bool) {
+		ast.Inspect(root, func(n ast.Node) bool {
+			switch expr := n.(type) {
+			case *ast.FuncLit:
+				if !inClosure {
+					inspectFn(expr.Body, true)
+					return false
+				}
+			case *ast.UnaryExpr:
+				if ident, ok := expr.X.(*ast.Ident); ok && expr.Op == token.AND {
+					escaped[ctx.Pass.TypesInfo.ObjectOf(ident)] = true
+				}
+			case *ast.AssignStmt:
+				if inClosure {
+					for _, lhs := range expr.Lhs {
+						if ident, ok := lhs.(*ast.Ident); ok {
+							escaped[ctx.Pass.TypesInfo.ObjectOf(ident)] = true
+						}
+					}
+				}
+			}
+			return true
+		})
+	}
+	inspectFn(body, false)
+	return escaped
+}
+
+// branchGuards returns the guards established when `cond` evaluates to `branch`
+func (fg *flowGraph) branchGuards(ctx passtyps.Context, cond ast.Expr, branch bool, errCalls errCalls) []*passtyps.ParamUsage {
+	switch expr := cond.(type) {
+	case *ast.ParenExpr:
+		return fg.branchGuards(ctx, expr.X, branch, errCalls)
+	case *ast.UnaryExpr:
+		if expr.Op == token.NOT {
+			return fg.branchGuards(ctx, expr.X, !branch, errCalls)
+		}
+	case *ast.Ident:
+		if assert, ok := fg.commaOk[ctx.Pass.TypesInfo.ObjectOf(expr)]; ok && branch {
+			return commaOkGuards(ctx, assert, expr)
+		}
+	case *ast.BinaryExpr:
+		switch expr.Op {
+		case token.LAND, token.LOR:
+			// `a && b` holding (`a || b` failing) means both operands hold (fail),
+			// otherwise only the guards common to either operand are known
+			lguards := fg.branchGuards(ctx, expr.X, branch, errCalls)
+			rguards := fg.branchGuards(ctx, expr.Y, branch, errCalls)
+			if branch == (expr.Op == token.LAND) {
+				return append(lguards, rguards...)
+			}
+			return guardSet{}.with(lguards).meet(guardSet{}.with(rguards)).guards()
+		}
+		if guards := fg.errCheckGuards(ctx, expr, branch, errCalls); guards != nil {
+			return guards
+		}
+		return runBinaryExpr(ctx, expr, branch)
+	}
+	return nil
+}
+
+// errCheckGuards returns the guards established by checking the error returned from a validator,
+// e.g., `validate(p) == nil`, or `err != nil` after `err := validate(p)`
+func (fg *flowGraph) errCheckGuards(ctx passtyps.Context, binaryExpr *ast.BinaryExpr, branch bool, errCalls errCalls) []*passtyps.ParamUsage {
+	op := binaryExpr.Op
+	if !branch {
+		op = common.NegateOp(op)
+	}
+	if op != token.EQL && op != token.NEQ {
+		return nil
+	}
+	errExpr := binaryExpr.X
+	if common.IsNilIdent(errExpr) {
+		errExpr = binaryExpr.Y
+	} else if !common.IsNilIdent(binaryExpr.Y) {
+		return nil
+	}
+
+	var callExpr *ast.CallExpr
+	switch expr := errExpr.(type) {
+	case *ast.CallExpr:
+		callExpr = expr
+	case *ast.Ident:
+		obj := ctx.Pass.TypesInfo.ObjectOf(expr)
+		if obj == nil || !common.IsErrorTyp(obj.Type()) {
+			return nil
+		}
+		if op == token.NEQ {
+			// remember the error is non-nil, so that returning it is known to be a failure
+			return []*passtyps.ParamUsage{passtyps.NewParamUsage(obj, binaryExpr, nil, obj.Pos())}
+		}
+		callExpr = errCalls[obj]
+	}
+	if callExpr == nil || op != token.EQL {
+		return nil
+	}
+	guards := fg.resultGuards(ctx, callExpr, binaryExpr)
+	if fn, summary := fg.summary(ctx, callExpr); summary != nil {
+		guards = append(guards, guardsForCall(ctx, callExpr, fn, summary.NilErrGuards)...)
+	}
+	return guards
+}
+
+// callGuards returns the guards established by the validators called in `n`, which hold regardless of their error
+func (fg *flowGraph) callGuards(ctx passtyps.Context, n ast.Node) []*passtyps.ParamUsage {
+	var guards []*passtyps.ParamUsage
+	ast.Inspect(n, func(n ast.Node) bool {
+		switch expr := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			if fn, summary := fg.summary(ctx, expr); summary != nil {
+				guards = append(guards, guardsForCall(ctx, expr, fn, summary.Guards)...)
+			}
+		}
+		return true
+	})
+	return guards
+}
+
+func (fg *flowGraph) summary(ctx passtyps.Context, callExpr *ast.CallExpr) (*types.Func, *passtyps.GuardFact) {
+	if fg.summaries == nil {
+		return nil, nil
+	}
+	fn := typeutil.StaticCallee(ctx.Pass.TypesInfo, callExpr)
+	if fn == nil {
+		return nil, nil
+	}
+	return fn, fg.summaries(fn)
+}
+
+// guardsForCall maps the parameters guarded by the callee `fn` to the arguments of `callExpr`
+func guardsForCall(ctx passtyps.Context, callExpr *ast.CallExpr, fn *types.Func, guarded []passtyps.GuardedParam) []*passtyps.ParamUsage {
+	var guards []*passtyps.ParamUsage
+	for _, g := range guarded {
+		var arg ast.Expr
+		if g.Index < 0 {
+			sel, ok := callExpr.Fun.(*ast.SelectorExpr)
+			if !ok {
+				continue
+			}
+			if selection := ctx.Pass.TypesInfo.Selections[sel]; selection == nil || len(selection.Index()) != 1 {
+				continue // promoted method: the receiver is a member of `sel.X`
+			}
+			arg = sel.X
+		} else if g.Index < len(callExpr.Args) {
+			arg = callExpr.Args[g.Index]
+		}
+		if arg == nil {
+			continue
+		}
+
+		root, path := common.MemberPath(ctx.Pass.TypesInfo, arg)
+		if _, ok := root.(*ast.Ident); !ok {
+			continue
+		}
+		v := common.IsTargetedParam(ctx, root)
+		if v == nil {
+			continue
+		}
+		typ := ctx.Pass.TypesInfo.TypeOf(arg)
+		for _, member := range g.Members {
+			field, _, _ := types.LookupFieldOrMethod(typ, true, fn.Pkg(), member)
+			if _, ok := field.(*types.Var); !ok {
+				path = nil
+				break
+			}
+			path = append(path, field)
+			typ = field.Type()
+		}
+		if len(path) == 0 && len(g.Members) > 0 {
+			continue
+		}
+
+		guard := passtyps.NewParamUsage(v, callExpr, nil, v.Pos())
+		if len(path) > 0 {
+			guard.Param = path[len(path)-1]
+			guard.Context = v
+			guard.Path = path
+		}
+		guard.MinLen = g.MinLen
+		guards = append(guards, guard)
+	}
+	return guards
+}
+
+func (s guardSet) guards() []*passtyps.ParamUsage {
+	guards := make([]*passtyps.ParamUsage, 0, len(s))
+	for _, guard := range s {
+		guards = append(guards, guard)
+	}
+	return guards
+}
+
+// neverReturns reports whether `n` is a call stopping the test through testing.TB.
+// Other calls that never return (panic, os.Exit, log.Fatal, t.Fatal, ...) already end their CFG block.
+func neverReturns(ctx passtyps.Context, n ast.Node) bool {
+	exprStmt, ok := n.(*ast.ExprStmt)
+	if !ok {
+		return false
+	}
+	callExpr, ok := exprStmt.X.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	fn, ok := typeutil.Callee(ctx.Pass.TypesInfo, callExpr).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != "testing" || !tbExits[fn.Name()] {
+		return false
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	return recv != nil && types.IsInterface(recv.Type())
+}
+
+// inspect visits the nodes in `root` with the guards holding when each of them is evaluated.
+// The right operand of && (||) is evaluated only if the left one is true (false),
+// so it is visited with the guards of that branch.
+func (fg *flowGraph) inspect(ctx passtyps.Context, root ast.Node, state guardSet, visit func(ast.Node, guardSet)) {
+	ast.Inspect(root, func(n ast.Node) bool {
+		if binaryExpr, ok := n.(*ast.BinaryExpr); ok && (binaryExpr.Op == token.LAND || binaryExpr.Op == token.LOR) {
+			rhsState := state.with(fg.branchGuards(ctx, binaryExpr.X, binaryExpr.Op == token.LAND, nil))
+			fg.inspect(ctx, binaryExpr.X, state, visit)
+			fg.inspect(ctx, binaryExpr.Y, rhsState, visit)
+			return false
+		}
+		if n != nil {
+			visit(n, state)
+		}
+		// A function literal is analyzed on its own, see funcLitAnalyzer.run
+		_, isFuncLit := n.(*ast.FuncLit)
+		return !isFuncLit
+	})
+}
+
+// unguardedUses returns the uses in `root` that are not covered by `state`
+func (fg *flowGraph) unguardedUses(ctx passtyps.Context, root ast.Node, state guardSet) []*passtyps.ParamUsage {
+	var uses []*passtyps.ParamUsage
+	fg.inspect(ctx, root, state, func(n ast.Node, state guardSet) {
+		var usages []*passtyps.ParamUsage
+		if !fg.isCommaOk(ctx, n) && !fg.selectOps[n] {
+			usages = runExpr(ctx, n)
+		}
+		if rangeOp, ok := fg.ranged[n]; ok {
+			usages = append(usages, paramOp(ctx, n.(ast.Expr), rangeOp, n)...)
+		}
+		usages = append(usages, fg.typedNils(ctx, n)...)
+		for _, usage := range usages {
+			if usage == nil || usage.UseAt == nil || fg.isRangeKeyIndex(ctx, usage) {
+				continue
+			}
+			if fg.locals.nilPtrs[usage.Param] && !usage.TypedNil {
+				continue // left to the nil facts
+			}
+			if guard := state[guardKey(usage)]; guard == nil || !sameAssertedTyp(usage, guard) {
+				uses = append(uses, usage)
+			} else if !coversIndex(ctx, usage, guard) {
+				usage.OutOfRange = true
+				uses = append(uses, usage)
+			}
+		}
+	})
+	return uses
+}
+
+// paramAliases finds the locals that always hold the value of a parameter, e.g., `q := p` or `q = p`.
+// Assigning anything else to the local, or reassigning the parameter, breaks the alias.
+func paramAliases(ctx passtyps.Context, body *ast.BlockStmt) map[types.Object]types.Object {
+	info := ctx.Pass.TypesInfo
+	sources := make(map[types.Object][]types.Object)
+	broken := make(map[types.Object]bool)
+	assign := func(lhs, rhs ast.Expr) {
+		ident, ok := unparen(lhs).(*ast.Ident)
+		if !ok {
+			return
+		}
+		obj := info.ObjectOf(ident)
+		if obj == nil {
+			return
+		}
+		if rhs == nil {
+			broken[obj] = true
+			return
+		}
+		if src, ok := unparen(rhs).(*ast.Ident); ok {
+			if v, ok := info.ObjectOf(src).(*types.Var); ok {
+				sources[obj] = append(sources[obj], v)
+				return
+			}
+		}
+		broken[obj] = true
+	}
+	assignAll := func(lhs, rhs []ast.Expr) {
+		for i, expr := range lhs {
+			if len(lhs) == len(rhs) {
+				assign(expr, rhs[i])
+			} else {
+				assign(expr, nil)
+			}
+		}
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch stmt := n.(type) {
+		case *ast.AssignStmt:
+			if stmt.Tok == token.ASSIGN || stmt.Tok == token.DEFINE {
+				assignAll(stmt.Lhs, stmt.Rhs)
+			} else {
+				assignAll(stmt.Lhs, nil)
+			}
+		case *ast.ValueSpec:
+			lhs := make([]ast.Expr, len(stmt.Names))
+			for i, name := range stmt.Names {
+				lhs[i] = name
+			}
+			assignAll(lhs, stmt.Values)
+		case *ast.RangeStmt:
+			assignAll([]ast.Expr{stmt.Key, stmt.Value}, nil)
+		case *ast.IncDecStmt:
+			assign(stmt.X, nil)
+		case *ast.UnaryExpr:
+			if stmt.Op == token.AND {
+				assign(stmt.X, nil)
+			}
+		}
+		return true
+	})
+
+	isParam := make(map[types.Object]bool)
+	for _, param := range ctx.Params {
+		isParam[param] = !broken[param] && len(sources[param]) == 0
+	}
+	aliases := make(map[types.Object]types.Object)
+	visiting := make(map[types.Object]bool)
+	var resolve func(obj types.Object) types.Object
+	resolve = func(obj types.Object) types.Object {
+		if isParam[obj] {
+			return obj
+		}
+		if param, ok := aliases[obj]; ok {
+			return param
+		}
+		if broken[obj] || len(sources[obj]) == 0 || visiting[obj] {
+			return nil
+		}
+		visiting[obj] = true
+		defer delete(visiting, obj)
+		var root types.Object
+		for _, src := range sources[obj] {
+			param := resolve(src)
+			if param == nil || (root != nil && param != root) {
+				return nil
+			}
+			root = param
+		}
+		aliases[obj] = root
+		return root
+	}
+	for obj := range sources {
+		resolve(obj)
+	}
+	return aliases
+}
+
+func unparen(expr ast.Expr) ast.Expr {
+	if paren, ok := expr.(*ast.ParenExpr); ok {
+		return unparen(paren.X)
+	}
+	return expr
+}
+
+// commaOkAsserts finds the comma-ok type assertions and map lookups, e.g., `v, ok := x.(T)` or `v, ok := m[k]`,
+// whose `ok` is never reassigned
+func commaOkAsserts(pass *analysis.Pass, body *ast.BlockStmt) map[types.Object]commaOkAssert {
+	info := pass.TypesInfo
+	asserts := make(map[types.Object]commaOkAssert)
+	assigned := make(map[types.Object]int)
+	record := func(lhs []ast.Expr, rhs []ast.Expr) {
+		for _, expr := range lhs {
+			if ident, ok := expr.(*ast.Ident); ok {
+				assigned[info.ObjectOf(ident)]++
+			}
+		}
+		if len(lhs) != 2 || len(rhs) != 1 {
+			return
+		}
+		okIdent, isIdent := lhs[1].(*ast.Ident)
+		if !isIdent {
+			return
+		}
+		var assert commaOkAssert
+		switch expr := unparen(rhs[0]).(type) {
+		case *ast.TypeAssertExpr:
+			if expr.Type == nil {
+				return
+			}
+			assert.expr = expr
+		case *ast.IndexExpr:
+			if _, isMap := common.CoreTyp(info.TypeOf(expr.X)).(*types.Map); !isMap {
+				return
+			}
+			assert.lookup = expr
+		default:
+			return
+		}
+		if value, ok := lhs[0].(*ast.Ident); ok && value.Name != "_" {
+			assert.value = value
+		}
+		if obj := info.ObjectOf(okIdent); obj != nil {
+			asserts[obj] = assert
+		}
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch stmt := n.(type) {
+		case *ast.FuncLit:
+			return false // analyzed on its own
+		case *ast.AssignStmt:
+			record(stmt.Lhs, stmt.Rhs)
+		case *ast.ValueSpec:
+			lhs := make([]ast.Expr, len(stmt.Names))
+			for i, name := range stmt.Names {
+				lhs[i] = name
+			}
+			record(lhs, stmt.Values)
+		}
+		return true
+	})
+	for obj := range asserts {
+		if assigned[obj] > 1 {
+			delete(asserts, obj)
+		}
+	}
+	return asserts
+}
+
+// isCommaOk reports whether `n` is the type assertion of a comma-ok assignment, which never panics
+func (fg *flowGraph) isCommaOk(ctx passtyps.Context, n ast.Node) bool {
+	typAssertExpr, ok := n.(*ast.TypeAssertExpr)
+	if !ok {
+		return false
+	}
+	for _, assert := range fg.commaOk {
+		if assert.expr == typAssertExpr {
+			return true
+		}
+	}
+	return false
+}
+
+// sameAssertedTyp reports whether the type asserted by the use, if any, is the one proven by its guard
+func sameAssertedTyp(usage, guard *passtyps.ParamUsage) bool {
+	return usage.AssertedTyp == nil || (guard.AssertedTyp != nil && types.Identical(usage.AssertedTyp, guard.AssertedTyp))
+}
+
+// assertedValues returns the values `v` of the comma-ok type assertions `v, ok := p.(T)` on the parameters,
+// which are nil unless `ok` holds if T is nilable
+func assertedValues(pass *analysis.Pass, body *ast.BlockStmt, params []types.Object) []types.Object {
+	var values []types.Object
+	for _, assert := range commaOkAsserts(pass, body) {
+		if assert.expr == nil || assert.value == nil {
+			continue
+		}
+		root, _ := common.MemberPath(pass.TypesInfo, unparen(assert.expr.X))
+		rootIdent, ok := root.(*ast.Ident)
+		if !ok || !findObj(params, pass.TypesInfo.ObjectOf(rootIdent)) {
+			continue
+		}
+		if obj := pass.TypesInfo.Defs[assert.value]; obj != nil && common.IsNilableTyp(obj.Type()) {
+			values = append(values, obj)
+		}
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].Pos() < values[j].Pos()
+	})
+	return values
+}
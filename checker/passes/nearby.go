@@ -0,0 +1,43 @@
+package passes
+
+import (
+	"go/ast"
+	"go/token"
+
+	"github.com/hyunsooda/paramguard/checker/passtyps"
+)
+
+// nearbyGuards notes on each use the closest check of its value preceding it in `body`, which does not cover it,
+// e.g., `if p == nil { log.Println(...) }` falling through to `*p`, or `len(b) > 0` before `b[1]`
+func nearbyGuards(ctx passtyps.Context, body *ast.BlockStmt, uses []*passtyps.ParamUsage) {
+	var checks []*ast.BinaryExpr // in source order
+	ast.Inspect(body, func(n ast.Node) bool {
+		if binaryExpr, ok := n.(*ast.BinaryExpr); ok {
+			switch binaryExpr.Op {
+			case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+				checks = append(checks, binaryExpr)
+			}
+		}
+		return true
+	})
+	for _, use := range uses {
+		for _, check := range checks {
+			if check.Pos() >= use.UseAt.Pos() {
+				break
+			}
+			if checksValue(ctx, check, use) {
+				use.NearbyGuard = check
+			}
+		}
+	}
+}
+
+// checksValue reports whether the comparison checks the value used, or its length
+func checksValue(ctx passtyps.Context, check *ast.BinaryExpr, use *passtyps.ParamUsage) bool {
+	for _, operand := range []ast.Expr{check.X, check.Y} {
+		if sameValue(paramValue(ctx, operand), use) || isLenOf(ctx, operand, use) {
+			return true
+		}
+	}
+	return false
+}
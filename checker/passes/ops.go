@@ -0,0 +1,276 @@
+package passes
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/hyunsooda/paramguard/checker/common"
+	"github.com/hyunsooda/paramguard/checker/passtyps"
+)
+
+// nilKind is the kind of a nilable value
+type nilKind int
+
+const (
+	kindOther nilKind = iota
+	kindPointer
+	kindSlice
+	kindMap
+	kindChan
+	kindFunc
+	kindInterface
+)
+
+// op is an operation applied to a value
+type op int
+
+const (
+	opDeref      op = iota // *p
+	opField                // p.f
+	opMethodCall           // x.M or x.M(), dispatched through the value (e.g., interface, value receiver)
+	opIndex                // x[i]
+	opIndexWrite           // x[i] = v, x[i] += v, x[i]++
+	opSlice                // x[i:j] with a non-zero bound
+	opSliceEmpty           // x[:], x[0:0]
+	opCall                 // f()
+	opClose                // close(ch)
+	opSend                 // ch <- v, blocking forever on nil
+	opRecv                 // <-ch, blocking forever on nil
+	opRange                // for range x, see flowGraph.ranged
+	opRangeValue           // for _, v := range x
+	opTypeAssert           // x.(T) without comma-ok, see flowGraph.commaOk
+)
+
+// nilPanics tells, per kind, the operations panicking (or blocking forever) on nil.
+// Everything else is safe on nil: reading, ranging over, `len` and `delete` of a nil map,
+// `len`, `cap`, `append`, `copy` and ranging over a nil slice, `clear` of both,
+// and calling a method with a pointer receiver (which may check the receiver itself).
+var nilPanics = map[nilKind]map[op]bool{
+	kindPointer: {
+		opDeref:      true,
+		opField:      true,
+		opMethodCall: true, // a value receiver is copied from *p
+		opIndex:      true, // pointer to array
+		opIndexWrite: true,
+		opSlice:      true,
+		opSliceEmpty: true,
+		opRangeValue: true,
+	},
+	kindSlice: {
+		opIndex:      true, // out of range
+		opIndexWrite: true,
+		opSlice:      true,
+	},
+	kindMap: {
+		opIndexWrite: true, // assignment to entry in nil map
+	},
+	kindChan: {
+		opClose:      true,
+		opSend:       true, // outside of select, see flowGraph.selectOps
+		opRecv:       true,
+		opRange:      true,
+		opRangeValue: true,
+	},
+	kindFunc: {
+		opCall: true,
+	},
+	kindInterface: {
+		opMethodCall: true,
+		opTypeAssert: true,
+	},
+}
+
+// opKind classifies `op` applied to a value of `kind` for the findings
+func opKind(op op, kind nilKind) passtyps.OpKind {
+	switch kind {
+	case kindSlice:
+		return passtyps.OpSliceIndex
+	case kindMap:
+		return passtyps.OpMapWrite
+	case kindChan:
+		return passtyps.OpChan
+	case kindFunc:
+		return passtyps.OpFuncCall
+	case kindInterface:
+		return passtyps.OpInterface
+	}
+	if op == opField {
+		return passtyps.OpMember
+	}
+	return passtyps.OpDeref
+}
+
+func kindOf(typ types.Type) nilKind {
+	switch common.CoreTyp(typ).(type) {
+	case *types.Pointer:
+		return kindPointer
+	case *types.Slice:
+		return kindSlice
+	case *types.Map:
+		return kindMap
+	case *types.Chan:
+		return kindChan
+	case *types.Signature:
+		return kindFunc
+	case *types.Interface:
+		return kindInterface
+	}
+	return kindOther
+}
+
+// paramOp returns the use of the parameter, or the member of a parameter, `x` if applying `op` to it panics
+// when it is nil, e.g., `s.items` in `s.items[0]`
+func paramOp(ctx passtyps.Context, x ast.Expr, op op, at ast.Node) []*passtyps.ParamUsage {
+	usage := paramValue(ctx, x)
+	if usage == nil || !nilPanics[kindOf(usage.Param.Type())][op] {
+		return nil
+	}
+	usage.UseAt = at
+	usage.Op = opKind(op, kindOf(usage.Param.Type()))
+	return []*passtyps.ParamUsage{usage}
+}
+
+// paramValue returns the parameter, the member of a parameter, or the element of either in the element mode,
+// that `x` evaluates to, neither guarded nor used
+func paramValue(ctx passtyps.Context, x ast.Expr) *passtyps.ParamUsage {
+	if indexExpr, ok := unparen(x).(*ast.IndexExpr); ok {
+		return elementValue(ctx, indexExpr)
+	}
+	root, path := common.MemberPath(ctx.Pass.TypesInfo, unparen(x))
+	if _, ok := root.(*ast.Ident); !ok {
+		return nil
+	}
+	v := common.IsTargetedParam(ctx, root)
+	if v == nil {
+		return nil
+	}
+	usage := passtyps.NewParamUsage(v, nil, nil, v.Pos())
+	if len(path) > 0 {
+		selectorExpr := unparen(x).(*ast.SelectorExpr)
+		member := paramMember(ctx, selectorExpr.Sel)
+		if member == nil {
+			return nil
+		}
+		usage.Param = member
+		usage.Context = v
+		usage.Path = path
+	}
+	return usage
+}
+
+// sameValue reports whether the usages refer to the same parameter or member
+func sameValue(a, b *passtyps.ParamUsage) bool {
+	return a != nil && b != nil && a.Param == b.Param && a.Context == b.Context
+}
+
+// selectorOp classifies the selection `x.sel`; the qualified identifiers select nothing from a value
+func selectorOp(ctx passtyps.Context, expr *ast.SelectorExpr) (op, bool) {
+	selection := ctx.Pass.TypesInfo.Selections[expr]
+	if selection == nil {
+		return 0, false
+	}
+	switch selection.Kind() {
+	case types.FieldVal:
+		return opField, true
+	case types.MethodVal:
+		if isPtrMethod(ctx, expr) {
+			// e.g., s.Validate(): a pointer receiver is passed without being dereferenced
+			return 0, false
+		}
+		return opMethodCall, true
+	}
+	return 0, false
+}
+
+// embeddedOps returns the uses of the embedded fields that the selection `x.sel` implicitly goes through,
+// e.g., `a.B` of `a.x` with `type A struct{ *B }`, or `a.Logger` of `a.Printf()` with `type A struct{ Logger }`
+func embeddedOps(ctx passtyps.Context, expr *ast.SelectorExpr) []*passtyps.ParamUsage {
+	selection := ctx.Pass.TypesInfo.Selections[expr]
+	if selection == nil || len(selection.Index()) < 2 {
+		return nil
+	}
+	base := paramValue(ctx, expr.X)
+	if base == nil {
+		return nil
+	}
+	context, path := base.Param, []types.Object(nil)
+	if base.Context != nil {
+		context, path = base.Context, base.Path
+	}
+
+	var usages []*passtyps.ParamUsage
+	typ := selection.Recv()
+	embedded := selection.Index()[:len(selection.Index())-1]
+	for i, index := range embedded {
+		structTyp, ok := common.CoreTyp(common.UnwrapPtrTyp(typ)).(*types.Struct)
+		if !ok {
+			break
+		}
+		field := structTyp.Field(index)
+		path = append(path[:len(path):len(path)], field)
+		typ = field.Type()
+		op := opField
+		if i == len(embedded)-1 && selection.Kind() == types.MethodVal {
+			// a pointer receiver is passed without being dereferenced, whereas a nil interface has no method
+			op = opMethodCall
+			if _, isPtr := selection.Obj().Type().(*types.Signature).Recv().Type().(*types.Pointer); isPtr {
+				continue
+			}
+		}
+		if !ctx.Members[field] || !nilPanics[kindOf(field.Type())][op] {
+			continue
+		}
+		usage := passtyps.NewParamUsage(field, nil, expr, context.Pos())
+		usage.Context = context
+		usage.Path = path
+		usage.Op = opKind(op, kindOf(field.Type()))
+		usages = append(usages, usage)
+	}
+	return usages
+}
+
+// sliceOp tells whether slicing may go out of the range of a nil value, e.g., `s[1:]`
+func sliceOp(ctx passtyps.Context, expr *ast.SliceExpr) op {
+	for _, bound := range []ast.Expr{expr.Low, expr.High, expr.Max} {
+		if bound == nil {
+			continue
+		}
+		if n, isConst := common.ConstInt(ctx.Pass.TypesInfo, bound); !isConst || n != 0 {
+			return opSlice
+		}
+	}
+	return opSliceEmpty
+}
+
+// builtinName returns the name of the builtin function called by `callExpr`, if any
+func builtinName(ctx passtyps.Context, callExpr *ast.CallExpr) string {
+	ident, ok := unparen(callExpr.Fun).(*ast.Ident)
+	if !ok {
+		return ""
+	}
+	if _, ok := ctx.Pass.TypesInfo.Uses[ident].(*types.Builtin); !ok {
+		return ""
+	}
+	return ident.Name
+}
+
+// writtenIndexes returns the index expressions written by an assignment, e.g., `m[k]` in `m[k] = v`
+func writtenIndexes(n ast.Node) []*ast.IndexExpr {
+	var lhs []ast.Expr
+	switch stmt := n.(type) {
+	case *ast.AssignStmt:
+		if stmt.Tok != token.DEFINE {
+			lhs = stmt.Lhs
+		}
+	case *ast.IncDecStmt:
+		lhs = []ast.Expr{stmt.X}
+	}
+	var indexes []*ast.IndexExpr
+	for _, expr := range lhs {
+		if indexExpr, ok := unparen(expr).(*ast.IndexExpr); ok {
+			indexes = append(indexes, indexExpr)
+		}
+	}
+	return indexes
+}
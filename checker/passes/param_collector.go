@@ -16,7 +16,7 @@ var ParamCollector = &analysis.Analyzer{
 	Doc:        "Assistant pass for ParamGuard analyzer",
 	Name:       "paramcollector",
 	Run:        runParamCollector,
-	Requires:   []*analysis.Analyzer{inspect.Analyzer, TypCollector},
+	Requires:   []*analysis.Analyzer{inspect.Analyzer, ConfigCollector},
 	ResultType: reflect.TypeOf(new(passtyps.FuncParams)),
 }
 
@@ -26,8 +26,7 @@ func runParamCollector(pass *analysis.Pass) (interface{}, error) {
 }
 
 func aggregateFuncParams(pass *analysis.Pass) passtyps.FuncParams {
-	config := passtyps.ParseConfig(pass)
-	namedTyps := pass.ResultOf[TypCollector].(*passtyps.StructTyps)
+	config := pass.ResultOf[ConfigCollector].(*passtyps.Config)
 	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
 	filterNodes := []ast.Node{
 		(*ast.FuncDecl)(nil),
@@ -39,8 +38,10 @@ func aggregateFuncParams(pass *analysis.Pass) passtyps.FuncParams {
 				if fn, ok := pass.TypesInfo.Defs[fnDecl.Name]; ok {
 					sig := fn.Type().(*types.Signature)
 					interestingParams := getNilableParams(sig.Params())
-					typCollection := getAllInnerTyps(pass, nil, interestingParams, *namedTyps)
-					funcParams[fn] = passtyps.NewParamWithTypCollection(interestingParams, typCollection)
+					if recv := checkedReceiver(sig, config); recv != nil {
+						interestingParams = append(interestingParams, recv)
+					}
+					funcParams[fn] = passtyps.NewParamWithMembers(interestingParams, getMembers(interestingParams))
 				}
 			}
 		}
@@ -52,47 +53,58 @@ func getNilableParams(params *types.Tuple) []types.Object {
 	var ptrParams []types.Object
 	for i := 0; i < params.Len(); i++ {
 		p := params.At(i)
-		switch p.Type().Underlying().(type) {
-		case *types.Pointer, *types.Slice, *types.Map, *types.Interface, *types.Signature, *types.Struct:
+		if common.IsNilableTyp(p.Type()) {
+			ptrParams = append(ptrParams, p)
+		} else if _, ok := common.CoreTyp(p.Type()).(*types.Struct); ok {
 			ptrParams = append(ptrParams, p)
 		}
 	}
 	return ptrParams
 }
 
-/// getAllInnerTyps recursively collect struct inner types
-func getAllInnerTyps(pass *analysis.Pass, collected passtyps.NamedTypes, params []types.Object, namedTyps map[types.Object]*ast.StructType) passtyps.NamedTypes {
-	m := make(passtyps.NamedTypes)
+// checkedReceiver returns the pointer receiver of a method if receivers are checked and its type is not nil-safe
+func checkedReceiver(sig *types.Signature, config *passtyps.Config) types.Object {
+	recv := sig.Recv()
+	if config == nil || !config.Receivers || recv == nil || recv.Name() == "" || recv.Name() == "_" {
+		return nil
+	}
+	ptr, ok := recv.Type().(*types.Pointer)
+	if !ok {
+		return nil
+	}
+	named, ok := ptr.Elem().(*types.Named)
+	if !ok {
+		return nil
+	}
+	obj := named.Obj()
+	if obj.Pkg() != nil && passtyps.IsNilSafe(config, obj.Pkg().Path(), obj.Pkg().Name(), obj.Name()) {
+		return nil
+	}
+	return recv
+}
 
+// getMembers collects the struct fields reachable from the parameters, whether the structs are declared
+// in another package, anonymous or instantiated from a generic type
+func getMembers(params []types.Object) passtyps.Members {
+	members := make(passtyps.Members)
 	for _, param := range params {
-		for declTyp, structTyp := range namedTyps {
-			objTyp := declTyp.Type()
-			_, ok := objTyp.(*types.Named)
-			if !ok { // Not a struct type
-				continue
-			}
-			if common.UnwrapPtrTyp(param.Type()) != objTyp {
-				continue
-			}
-			m[objTyp] = make(map[*ast.Ident]types.Object)
-			for _, targetField := range structTyp.Fields.List {
-				for _, targetFieldNm := range targetField.Names {
-					fieldObj := pass.TypesInfo.Defs[targetFieldNm]
-					m[objTyp][targetFieldNm] = fieldObj
-
-					// self-reference structure create infinite loop
-					if common.IsCollected(collected, fieldObj) {
-						continue
-					}
+		collectMembers(members, param.Type())
+	}
+	return members
+}
 
-					for _, innerTyps := range getAllInnerTyps(pass, common.MapMerge(m, collected), []types.Object{fieldObj}, namedTyps) {
-						for innerNm, innerT := range innerTyps {
-							m[objTyp][innerNm] = innerT
-						}
-					}
-				}
-			}
+func collectMembers(members passtyps.Members, typ types.Type) {
+	structTyp, ok := common.CoreTyp(common.UnwrapPtrTyp(typ)).(*types.Struct)
+	if !ok {
+		return
+	}
+	for i := 0; i < structTyp.NumFields(); i++ {
+		field := structTyp.Field(i)
+		// self-reference structure create infinite loop
+		if members[field] {
+			continue
 		}
+		members[field] = true
+		collectMembers(members, field.Type())
 	}
-	return m
 }
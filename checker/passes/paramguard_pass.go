@@ -5,266 +5,526 @@ import (
 	"go/ast"
 	"go/token"
 	"go/types"
+	"reflect"
+	"sort"
 
+	"github.com/hyunsooda/paramguard/checker/callgraph"
 	"github.com/hyunsooda/paramguard/checker/common"
-	"github.com/hyunsooda/paramguard/checker/passes/test"
 	"github.com/hyunsooda/paramguard/checker/passtyps"
 	"github.com/hyunsooda/paramguard/checker/report"
 	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/ctrlflow"
 	"golang.org/x/tools/go/analysis/passes/inspect"
 	"golang.org/x/tools/go/ast/inspector"
 )
 
 var MainAnalyzer = &analysis.Analyzer{
-	Doc:      "Perform static analysis on Go source files to identify unsafe practices, such as nil dereferences, using a heuristic-based approach.",
-	Name:     "paramguard",
-	Run:      run,
-	Requires: []*analysis.Analyzer{inspect.Analyzer, ParamCollector},
+	Doc:        "Perform static analysis on Go source files to identify unsafe practices, such as nil dereferences, using a heuristic-based approach.",
+	Name:       "paramguard",
+	Run:        run,
+	Requires:   []*analysis.Analyzer{inspect.Analyzer, ctrlflow.Analyzer, ConfigCollector, ParamCollector, SummaryCollector, callgraph.Analyzer},
+	ResultType: reflect.TypeOf([]*report.Finding(nil)),
 }
 
 func Init() {
 	customFlags := flag.NewFlagSet("paramguard-flags", flag.ExitOnError)
 	customFlags.String(passtyps.FLAG_CONFIG_FILE_PATH, "", "Set the configuration file path (default=none)")
+	customFlags.Bool(passtyps.FLAG_RECEIVERS, false, "Check pointer receivers as nilable parameters (default=false)")
+	customFlags.Bool(passtyps.FLAG_ELEMENTS, false, "Check the elements of slices and maps as nilable values (default=false)")
 	MainAnalyzer.Flags = *customFlags
-	ParamCollector.Flags = *customFlags
+	ConfigCollector.Flags = *customFlags
 }
 
 func run(pass *analysis.Pass) (interface{}, error) {
-	config := passtyps.ParseConfig(pass)
+	config := pass.ResultOf[ConfigCollector].(*passtyps.Config)
 	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	cfgs := pass.ResultOf[ctrlflow.Analyzer].(*ctrlflow.CFGs)
 	funcParams := *pass.ResultOf[ParamCollector].(*passtyps.FuncParams)
+	summaries := *pass.ResultOf[SummaryCollector].(*passtyps.GuardSummaries)
 	filterNodes := []ast.Node{
 		(*ast.FuncDecl)(nil),
 	}
+	var (
+		results []funcResult
+		uses    []*passtyps.ParamUsage
+	)
+	sites := newCallSites()
 	insp.Preorder(filterNodes, func(n ast.Node) {
 		if fnDecl, ok := n.(*ast.FuncDecl); ok {
-			if !passtyps.IsInExcludes(pass, fnDecl, config) {
-				if fn, ok := pass.TypesInfo.Defs[fnDecl.Name]; ok {
-					interestingParams := funcParams[fn].Params
-					if len(interestingParams) > 0 {
-						ctx := passtyps.NewContext(pass, interestingParams, funcParams[fn].TypCollection)
-						unsanitized := runBlk(ctx, fnDecl.Body, fn.(*types.Func))
-						report.AddReports(pass, fn, unsanitized)
-						test.ReportOnTest(pass, unsanitized)
-					}
+			fn, ok := pass.TypesInfo.Defs[fnDecl.Name].(*types.Func)
+			g := cfgs.FuncDecl(fnDecl)
+			if !ok || g == nil {
+				return
+			}
+			var interestingParams []types.Object
+			members := funcParams[fn].Members
+			excluded := passtyps.IsInExcludes(pass, fnDecl, config)
+			if !excluded {
+				interestingParams = funcParams[fn].Params
+			}
+			if !excluded {
+				if asserted := assertedValues(pass, fnDecl.Body, interestingParams); len(asserted) > 0 {
+					interestingParams = append(interestingParams, asserted...)
+					members = getMembers(interestingParams)
 				}
 			}
+			// The functions without interesting parameters are still walked for their call sites
+			ctx := passtyps.NewContext(pass, interestingParams, members)
+			var locals trackedLocals
+			if !excluded {
+				if config != nil && config.Elements {
+					ctx.Elements = make(map[passtyps.ElementKey]*passtyps.Element)
+				}
+				locals = trackLocals(&ctx, fnDecl.Body, config, summaries)
+			}
+			ctx.Aliases = paramAliases(ctx, fnDecl.Body)
+			fg := newFlowGraph(ctx, g, fnDecl.Body, lookupSummary(summaries))
+			fg.locals = locals
+			fg.sig = fn.Type().(*types.Signature)
+			unsanitized, lits := runBlk(ctx, fg, fn, sites)
+			litAnalyzer := &funcLitAnalyzer{cfgs, summaries, sites, config, excluded}
+			unsanitized = append(unsanitized, litAnalyzer.run(ctx, lits, fn)...)
+			nearbyGuards(ctx, fnDecl.Body, unsanitized)
+			if len(unsanitized) > 0 {
+				results = append(results, funcResult{fn, unsanitized})
+				uses = append(uses, unsanitized...)
+			}
 		}
 	})
-	report.PrintReports(pass)
-	return nil, nil
+	var findings []*report.Finding
+	for _, result := range results {
+		unsanitized := filterByCallers(pass, config, sites, result.unsanitized)
+		findings = append(findings, report.NewFindings(pass, unsanitized)...)
+	}
+	if config != nil && config.CallGraph {
+		maxLen := config.Maxpath
+		if maxLen == 0 {
+			maxLen = callgraph.DefaultMaxpath
+		}
+		graph := pass.ResultOf[callgraph.Analyzer].(*callgraph.Graph)
+		for _, f := range findings {
+			paths, err := graph.CallPaths(f.Func, maxLen)
+			if err != nil {
+				return nil, err
+			}
+			f.CallPaths = paths
+		}
+	}
+	confirmed := confirmNilCalls(pass, sites, uses, summaries)
+	findings = append(findings, report.NewConfirmedFindings(pass, confirmed)...)
+	return report.Report(pass, findings), nil
 }
 
-func runBlk(ctx passtyps.Context, blkStmt *ast.BlockStmt, fn *types.Func) []*passtyps.ParamUsage {
-	var (
-		uses         []*passtyps.ParamUsage
-		guards       []*passtyps.ParamUsage
-		sanitizedUse = make(map[*passtyps.ParamUsage]bool)
-		unsanitized  []*passtyps.ParamUsage
-	)
-	if blkStmt != nil {
-		ast.Inspect(blkStmt, func(n ast.Node) bool {
-			paramUsages := runExpr(ctx, n)
-			for _, usage := range paramUsages {
-				if usage != nil {
-					if usage.GuardAt != nil {
-						guards = append(guards, usage)
-					}
-					if usage.UseAt != nil {
-						uses = append(uses, usage)
-					}
+type funcResult struct {
+	fn          types.Object
+	unsanitized []*passtyps.ParamUsage
+}
 
-				}
+// runBlk reports the uses that are not dominated by a guard on the branch where the parameter is non-nil.
+// It also returns the function literals created in the body, with the guards holding at their creation.
+func runBlk(ctx passtyps.Context, fg *flowGraph, fn *types.Func, sites *callSites) ([]*passtyps.ParamUsage, map[*ast.FuncLit]guardSet) {
+	var unsanitized []*passtyps.ParamUsage
+	lits := make(map[*ast.FuncLit]guardSet)
+	in := fg.solve(ctx)
+	for _, blk := range fg.g.Blocks {
+		state, reached := in[blk]
+		if !blk.Live || !reached {
+			continue
+		}
+		fg.walk(ctx, blk, state, func(n ast.Node, state guardSet) {
+			for _, use := range fg.unguardedUses(ctx, n, state) {
+				use.Fn = fn
+				unsanitized = append(unsanitized, use)
 			}
-			return true
+			fg.inspect(ctx, n, state, func(n ast.Node, state guardSet) {
+				sites.record(ctx, n, state)
+				if lit, ok := n.(*ast.FuncLit); ok {
+					lits[lit] = state
+				}
+			})
 		})
 	}
+	return unsanitized, lits
+}
 
-	for _, guard := range guards {
-		for _, use := range uses {
-			if guard.Param.Id() == use.Param.Id() &&
-				guard.GuardAt.Pos() <= use.UseAt.Pos() {
-				// violated: guard expression must appear before the use expression
-				sanitizedUse[use] = true
-			}
-		}
+// funcLitAnalyzer analyzes the function literals as their own units
+type funcLitAnalyzer struct {
+	cfgs      *ctrlflow.CFGs
+	summaries passtyps.GuardSummaries
+	sites     *callSites
+	config    *passtyps.Config
+	excluded  bool // the enclosing function is excluded by the configuration
+}
+
+// run analyzes the literals with their own parameters and the parameters captured from the enclosing function.
+// The guards holding where a literal is created (e.g., before `go func() {...}()`) still hold inside it,
+// whereas the guards inside it never protect the enclosing function.
+func (a *funcLitAnalyzer) run(ctx passtyps.Context, lits map[*ast.FuncLit]guardSet, fn *types.Func) []*passtyps.ParamUsage {
+	sorted := make([]*ast.FuncLit, 0, len(lits))
+	for lit := range lits {
+		sorted = append(sorted, lit)
 	}
-	for _, use := range uses {
-		asserted := false
-		for sanitized := range sanitizedUse {
-			if use == sanitized {
-				asserted = true
-				break
-			}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Pos() < sorted[j].Pos()
+	})
+
+	var unsanitized []*passtyps.ParamUsage
+	for _, lit := range sorted {
+		g := a.cfgs.FuncLit(lit)
+		if g == nil {
+			continue
+		}
+		params := append([]types.Object{}, ctx.Params...)
+		members := ctx.Members
+		if !a.excluded {
+			litParams := getNilableParams(ctx.Pass.TypesInfo.TypeOf(lit).(*types.Signature).Params())
+			params = append(params, litParams...)
+			params = append(params, assertedValues(ctx.Pass, lit.Body, params)...)
+			members = getMembers(params)
+		}
+		litCtx := passtyps.NewContext(ctx.Pass, params, members)
+		var locals trackedLocals
+		if !a.excluded {
+			litCtx.Elements = ctx.Elements
+			locals = trackLocals(&litCtx, lit.Body, a.config, a.summaries)
+		}
+		litCtx.Aliases = paramAliases(litCtx, lit.Body)
+		for local, param := range ctx.Aliases {
+			litCtx.Aliases[local] = param
 		}
-		if !asserted {
-			use.Fn = fn
-			unsanitized = append(unsanitized, use)
+
+		fg := newFlowGraph(litCtx, g, lit.Body, lookupSummary(a.summaries))
+		fg.locals = locals
+		fg.sig = ctx.Pass.TypesInfo.TypeOf(lit).(*types.Signature)
+		// The literal may run after the captured locals are assigned, so only the guards on the parameters are kept
+		fg.entry = guardSet{}
+		for k, guard := range lits[lit] {
+			if !guard.IsNil && (findObj(ctx.Params, guard.Param) || findObj(ctx.Params, guard.Context)) {
+				fg.entry[k] = guard
+			}
 		}
+		uses, nested := runBlk(litCtx, fg, fn, a.sites)
+		unsanitized = append(unsanitized, uses...)
+		unsanitized = append(unsanitized, a.run(litCtx, nested, fn)...)
 	}
 	return unsanitized
 }
 
+// trackedLocals are the locals checked alongside the parameters
+type trackedLocals struct {
+	errResults map[*ast.CallExpr][]types.Object // results of the calls, non-nil once their error is nil
+	nilPtrs    map[types.Object]bool            // pointers declared nil, only checked for their conversions to interfaces
+}
+
+// trackLocals adds the locals checked alongside the parameters to the context: the elements in the element mode,
+// the nilable results of calls, and the pointers declared nil
+func trackLocals(ctx *passtyps.Context, body *ast.BlockStmt, config *passtyps.Config, summaries passtyps.GuardSummaries) trackedLocals {
+	if ctx.Elements != nil {
+		ctx.Params = append(ctx.Params, elementValues(*ctx, body)...)
+	}
+	results, errResults := callResults(*ctx, body, config, lookupSummary(summaries))
+	ctx.Params = append(ctx.Params, results...)
+	locals := trackedLocals{errResults: errResults, nilPtrs: make(map[types.Object]bool)}
+	for _, ptr := range nilPointers(*ctx, body) {
+		ctx.Params = append(ctx.Params, ptr)
+		locals.nilPtrs[ptr] = true
+	}
+	return locals
+}
+
+func findObj(objs []types.Object, target types.Object) bool {
+	for _, obj := range objs {
+		if obj == target {
+			return true
+		}
+	}
+	return false
+}
+
+// runExpr returns the uses of the parameters in `n` that panic when they are nil, see nilPanics
 func runExpr(ctx passtyps.Context, n ast.Node) []*passtyps.ParamUsage {
 	switch expr := n.(type) {
+	case *ast.AssignStmt, *ast.IncDecStmt:
+		var usages []*passtyps.ParamUsage
+		for _, indexExpr := range writtenIndexes(expr) {
+			usages = append(usages, paramOp(ctx, indexExpr.X, opIndexWrite, indexExpr)...)
+		}
+		return usages
 	case *ast.CallExpr:
-		if fnIdent := common.IsTargetedParam(ctx, expr.Fun); fnIdent != nil {
-			return []*passtyps.ParamUsage{passtyps.NewParamUsage(fnIdent, nil, expr, fnIdent.Pos())}
+		switch builtinName(ctx, expr) {
+		case "":
+			return paramOp(ctx, expr.Fun, opCall, expr)
+		case "close":
+			if len(expr.Args) == 1 {
+				return paramOp(ctx, expr.Args[0], opClose, expr)
+			}
+		}
+	case *ast.SendStmt:
+		return paramOp(ctx, expr.Chan, opSend, expr)
+	case *ast.UnaryExpr:
+		if expr.Op == token.ARROW {
+			return paramOp(ctx, expr.X, opRecv, expr)
 		}
-	case *ast.BinaryExpr:
-		return runBinaryExpr(ctx, expr)
-	case *ast.TypeSwitchStmt:
-		return runTypSwitchStmt(ctx, expr)
 	case *ast.StarExpr:
 		children := common.GetSelectorExprChildren(expr.X)
 		// depth n: e.g., *s.member1.member2
 		if len(children) > 0 {
 			mostParentExpr := children[len(children)-1].X
-			lastChildIdent := children[0].Sel
 			if v := common.IsTargetedParam(ctx, mostParentExpr); v != nil {
-				for _, innerChildren := range ctx.TypCollection {
-					for ident, typ := range innerChildren {
-						if ident.Name == lastChildIdent.Name {
-							if _, ok := typ.Type().Underlying().(*types.Pointer); ok {
-								usage := ctx.Pass.TypesInfo.Uses[lastChildIdent]
-								paramUsage := passtyps.NewParamUsage(usage, nil, expr, v.Pos())
-								paramUsage.Context = v
-								return []*passtyps.ParamUsage{paramUsage}
-							}
-						}
-					}
+				if member := paramMember(ctx, children[0].Sel); member != nil && kindOf(member.Type()) == kindPointer {
+					paramUsage := passtyps.NewParamUsage(member, nil, expr, v.Pos())
+					paramUsage.Context = v
+					_, paramUsage.Path = common.MemberPath(ctx.Pass.TypesInfo, children[0])
+					paramUsage.Op = passtyps.OpDeref
+					return []*passtyps.ParamUsage{paramUsage}
 				}
 			}
 		}
 
 		// depth 1: e.g., *v
-		if v := common.IsTargetedParam(ctx, expr); v != nil {
-			return []*passtyps.ParamUsage{passtyps.NewParamUsage(v, nil, expr, v.Pos())}
-		}
-	case *ast.SelectorExpr: // rule: if a struct is pointer && access its member
+		return paramOp(ctx, expr.X, opDeref, expr)
+	case *ast.SelectorExpr:
+		// implicit: e.g., a.B of a.x promoted from the embedded *B
+		usages := embeddedOps(ctx, expr)
+
 		// depth n: e.g., s.member1.member2
 		if paramUsage := runSelectorExprTree(ctx, expr, true); paramUsage != nil {
-			return paramUsage
+			return append(paramUsage, usages...)
 		}
 
-		// depth 1: e.g., s.member
-		if v := common.IsTargetedParam(ctx, expr.X); v != nil {
-			switch v.Type().Underlying().(type) {
-			case *types.Pointer, *types.Interface:
-				return []*passtyps.ParamUsage{passtyps.NewParamUsage(v, nil, expr, v.Pos())}
-			}
+		// depth 1: e.g., s.member, s.Method
+		if op, ok := selectorOp(ctx, expr); ok {
+			return append(paramOp(ctx, expr.X, op, expr), usages...)
 		}
+		return usages
 	case *ast.SliceExpr:
-		if v := common.IsTargetedParam(ctx, expr.X); v != nil {
-			return []*passtyps.ParamUsage{passtyps.NewParamUsage(v, nil, expr, v.Pos())}
-		}
+		return paramOp(ctx, expr.X, sliceOp(ctx, expr), expr)
 	case *ast.IndexExpr:
-		if v := common.IsTargetedParam(ctx, expr.X); v != nil {
-			return []*passtyps.ParamUsage{passtyps.NewParamUsage(v, nil, expr, v.Pos())}
+		return paramOp(ctx, expr.X, opIndex, expr)
+	case *ast.TypeAssertExpr:
+		if expr.Type == nil { // x.(type) of a type switch
+			return nil
+		}
+		usages := paramOp(ctx, expr.X, opTypeAssert, expr)
+		for _, usage := range usages {
+			usage.AssertedTyp = ctx.Pass.TypesInfo.TypeOf(expr.Type)
 		}
+		return usages
 	}
 	return nil
 }
 
-func lenCompGuard(ctx passtyps.Context, expr ast.Expr) *passtyps.ParamUsage {
+func isPtrMethod(ctx passtyps.Context, expr *ast.SelectorExpr) bool {
+	selection := ctx.Pass.TypesInfo.Selections[expr]
+	if selection == nil || selection.Kind() != types.MethodVal || len(selection.Index()) != 1 {
+		return false
+	}
+	_, ok := selection.Obj().Type().(*types.Signature).Recv().Type().(*types.Pointer)
+	return ok
+}
+
+func lenCompGuard(ctx passtyps.Context, binaryExpr *ast.BinaryExpr, expr ast.Expr, op token.Token, bound ast.Expr) *passtyps.ParamUsage {
 	if callExpr, isCallExpr := expr.(*ast.CallExpr); isCallExpr {
 		if fnIdent := common.Cast2Ident(callExpr); fnIdent != nil && fnIdent.Name == "len" {
-			if lenParam := common.IsTargetedParam(ctx, callExpr.Args[0]); lenParam != nil {
-				return passtyps.NewParamUsage(lenParam, expr, nil, lenParam.Pos())
+			// e.g., len(b) > 0, len(s.items) > 0
+			if usage := paramValue(ctx, callExpr.Args[0]); usage != nil {
+				if !common.IsSliceTyp(usage.Param) {
+					return nil
+				}
+				usage.MinLen, usage.LenAbove, usage.LenMargin = provenLen(ctx, op, bound)
+				// e.g., not `len(b) >= n` unless `b[:n]`, or `b[n-1]`, is used
+				if impliesNonEmpty(ctx, op, bound) || usage.LenAbove != nil {
+					usage.GuardAt = binaryExpr
+					return usage
+				}
 			}
 		}
 	}
 	return nil
 }
 
-func runBinaryExpr(ctx passtyps.Context, binaryExpr *ast.BinaryExpr) []*passtyps.ParamUsage {
-	// Guard Definition 1
-	lhs, op, rhs := binaryExpr.X, binaryExpr.Op, binaryExpr.Y
+// impliesNonEmpty reports whether `len(x) <op> bound` implies `len(x) > 0`
+func impliesNonEmpty(ctx passtyps.Context, op token.Token, bound ast.Expr) bool {
+	n, isConst := common.ConstInt(ctx.Pass.TypesInfo, bound)
+	switch op {
+	case token.GTR:
+		return !isConst || n >= 0
+	case token.GEQ:
+		return isConst && n >= 1 // e.g., not `len(x) >= n`, as `n` may be 0
+	case token.NEQ:
+		return isConst && n == 0
+	case token.EQL:
+		return isConst && n >= 1
+	}
+	return false
+}
 
-	// N detpth
-	if paramUsages := runSelectorExprTree(ctx, lhs, false); paramUsages != nil {
-		for _, usage := range paramUsages {
-			usage.GuardAt = binaryExpr
+// provenLen returns the minimum length proven by `len(x) <op> bound`, and the variable bounding the length
+// from below with its margin, e.g., `i` and 1 in `len(x) > i`, or `k` and 0 in `len(x) >= k`
+func provenLen(ctx passtyps.Context, op token.Token, bound ast.Expr) (int, types.Object, int) {
+	n, isConst := common.ConstInt(ctx.Pass.TypesInfo, bound)
+	if !isConst {
+		ident, ok := unparen(bound).(*ast.Ident)
+		switch {
+		case ok && op == token.GTR:
+			return 1, ctx.Pass.TypesInfo.ObjectOf(ident), 1
+		case ok && op == token.GEQ:
+			return 0, ctx.Pass.TypesInfo.ObjectOf(ident), 0 // `k` may be 0
+		case op == token.GTR:
+			return 1, nil, 0
 		}
-		return paramUsages
+		return 0, nil, 0
 	}
-	if paramUsages := runSelectorExprTree(ctx, rhs, false); paramUsages != nil {
-		for _, usage := range paramUsages {
-			usage.GuardAt = binaryExpr
-		}
-		return paramUsages
+	switch op {
+	case token.GTR:
+		return int(n) + 1, nil, 0
+	case token.GEQ, token.EQL:
+		return int(n), nil, 0
 	}
+	return 1, nil, 0
+}
 
-	lchildren := common.GetSelectorExprChildren(lhs)
-	if len(lchildren) > 0 {
-		lhs = lchildren[len(lchildren)-1].X
-	}
-	rchildren := common.GetSelectorExprChildren(rhs)
-	if len(rchildren) > 0 {
-		rhs = rchildren[len(rchildren)-1].X
+// runBinaryExpr returns the guards established when `binaryExpr` evaluates to `branch`
+func runBinaryExpr(ctx passtyps.Context, binaryExpr *ast.BinaryExpr, branch bool) []*passtyps.ParamUsage {
+	lhs, op, rhs := binaryExpr.X, binaryExpr.Op, binaryExpr.Y
+	if !branch {
+		op = common.NegateOp(op)
 	}
-	lhsV := common.IsTargetedParam(ctx, lhs)
-	rhsV := common.IsTargetedParam(ctx, rhs)
 
-	if op == token.EQL || op == token.NEQ {
-		// 0 or 1 depth
-		if lhsV != nil {
-			if rhsIdent := common.Cast2Ident(rhs); rhsIdent != nil && rhsIdent.Name == "nil" {
-				paramUsage := passtyps.NewParamUsage(lhsV, binaryExpr, nil, lhsV.Pos())
-				if len(lchildren) > 0 {
-					paramUsage.Param = ctx.Pass.TypesInfo.Uses[lchildren[0].Sel]
-				}
-				return []*passtyps.ParamUsage{paramUsage}
-			}
+	// Guard Definition 1
+	if op == token.NEQ {
+		if common.IsNilIdent(rhs) {
+			return nilCompGuard(ctx, binaryExpr, lhs)
 		}
-		if rhsV != nil {
-			if lhsIdent := common.Cast2Ident(lhs); lhsIdent != nil && lhsIdent.Name == "nil" {
-				paramUsage := passtyps.NewParamUsage(rhsV, binaryExpr, nil, rhsV.Pos())
-				if len(rchildren) > 0 {
-					paramUsage.Param = ctx.Pass.TypesInfo.Uses[rchildren[0].Sel]
-				}
-				return []*passtyps.ParamUsage{paramUsage}
+		if common.IsNilIdent(lhs) {
+			return nilCompGuard(ctx, binaryExpr, rhs)
+		}
+	}
+	// The opposite branch, on which the parameter is known to be nil
+	if op == token.EQL {
+		expr := lhs
+		if common.IsNilIdent(lhs) {
+			expr = rhs
+		} else if !common.IsNilIdent(rhs) {
+			expr = nil
+		}
+		if expr != nil {
+			nilFacts := nilCompGuard(ctx, binaryExpr, expr)
+			for _, nilFact := range nilFacts {
+				nilFact.IsNil = true
 			}
+			return nilFacts
 		}
 	}
 
 	// Guard Definition 2
-	if op == token.EQL || op == token.NEQ || op == token.LEQ || op == token.GEQ || op == token.LSS || op == token.GTR {
-		if lenParamUsage := lenCompGuard(ctx, lhs); lenParamUsage != nil {
-			if common.IsSliceTyp(lenParamUsage.Param) {
-				return []*passtyps.ParamUsage{lenParamUsage}
-			}
+	if lenParamUsage := lenCompGuard(ctx, binaryExpr, lhs, op, rhs); lenParamUsage != nil {
+		return []*passtyps.ParamUsage{lenParamUsage}
+	}
+	if lenParamUsage := lenCompGuard(ctx, binaryExpr, rhs, common.MirrorOp(op), lhs); lenParamUsage != nil {
+		return []*passtyps.ParamUsage{lenParamUsage}
+	}
+	return nil
+}
+
+func nilCompGuard(ctx passtyps.Context, guardAt ast.Node, expr ast.Expr) []*passtyps.ParamUsage {
+	// an element in the element mode, e.g., items[i] != nil
+	if indexExpr, ok := unparen(expr).(*ast.IndexExpr); ok {
+		if element := elementValue(ctx, indexExpr); element != nil {
+			element.GuardAt = guardAt
+			return []*passtyps.ParamUsage{element}
 		}
-		if lenParamUsage := lenCompGuard(ctx, rhs); lenParamUsage != nil {
-			if common.IsSliceTyp(lenParamUsage.Param) {
-				return []*passtyps.ParamUsage{lenParamUsage}
-			}
+		return nil
+	}
+	root, path := common.MemberPath(ctx.Pass.TypesInfo, expr)
+
+	// N detpth
+	if paramUsages := runSelectorExprTree(ctx, expr, false); paramUsages != nil {
+		for _, usage := range paramUsages {
+			usage.GuardAt = guardAt
+			usage.Path = path
+		}
+		return paramUsages
+	}
+
+	// 0 or 1 depth
+	if v := common.IsTargetedParam(ctx, root); v != nil {
+		paramUsage := passtyps.NewParamUsage(v, guardAt, nil, v.Pos())
+		if len(path) > 0 {
+			paramUsage.Param = path[len(path)-1]
+			paramUsage.Context = v
+			paramUsage.Path = path
 		}
+		return []*passtyps.ParamUsage{paramUsage}
 	}
 	return nil
 }
 
-func runTypSwitchStmt(ctx passtyps.Context, typSwitchStmt *ast.TypeSwitchStmt) []*passtyps.ParamUsage {
-	// Guard Definition 3
-	if assignExpr, ok := typSwitchStmt.Assign.(*ast.ExprStmt); ok {
-		if typAssertExpr, ok := assignExpr.X.(*ast.TypeAssertExpr); ok {
+// runTypSwitchStmt returns the guards established when the type switch subject matches (`branch`) `caseTyp`
+func runTypSwitchStmt(ctx passtyps.Context, typSwitchStmt *ast.TypeSwitchStmt, caseTyp ast.Expr, branch bool) []*passtyps.ParamUsage {
+	// Guard Definition 3: the subject is non-nil inside a non-nil case and after passing `case nil`
+	if common.IsNilIdent(caseTyp) == branch {
+		return nil
+	}
+	var typAssertExpr *ast.TypeAssertExpr
+	switch assign := typSwitchStmt.Assign.(type) {
+	case *ast.ExprStmt: // e.g., switch itf.(type)
+		typAssertExpr, _ = assign.X.(*ast.TypeAssertExpr)
+	case *ast.AssignStmt: // e.g., switch v := itf.(type)
+		if len(assign.Rhs) == 1 {
+			typAssertExpr, _ = assign.Rhs[0].(*ast.TypeAssertExpr)
+		}
+	}
+	if typAssertExpr == nil {
+		return nil
+	}
 
-			// depth n: e.g., s.member1.member2.(type)
-			if paramUsage := runSelectorExprTree(ctx, typAssertExpr.X, false); paramUsage != nil {
-				return paramUsage
+	// depth n: e.g., s.member1.member2.(type)
+	if paramUsages := runSelectorExprTree(ctx, typAssertExpr.X, false); paramUsages != nil {
+		_, path := common.MemberPath(ctx.Pass.TypesInfo, typAssertExpr.X)
+		for _, usage := range paramUsages {
+			usage.GuardAt = typSwitchStmt
+			usage.Path = path
+			if branch {
+				usage.AssertedTyp = ctx.Pass.TypesInfo.TypeOf(caseTyp)
 			}
+		}
+		return paramUsages
+	}
 
-			// depth 1: e.g., itf.(type)
-			if v := common.IsTargetedParam(ctx, typAssertExpr.X); v != nil {
-				if _, ok := v.Type().Underlying().(*types.Interface); ok {
-					return []*passtyps.ParamUsage{passtyps.NewParamUsage(v, typSwitchStmt, nil, v.Pos())}
-				}
+	// depth 1: e.g., itf.(type)
+	if v := common.IsTargetedParam(ctx, typAssertExpr.X); v != nil {
+		if _, ok := v.Type().Underlying().(*types.Interface); ok {
+			usage := passtyps.NewParamUsage(v, typSwitchStmt, nil, v.Pos())
+			if branch {
+				usage.AssertedTyp = ctx.Pass.TypesInfo.TypeOf(caseTyp)
 			}
+			return []*passtyps.ParamUsage{usage}
 		}
 	}
 	return nil
 }
 
+// commaOkGuards returns the guards established when the `ok` of `v, ok := x.(T)` holds:
+// x holds a T, and v is non-nil unless T is a non-nilable type.
+// For `v, ok := m[k]`, m holds k, and its value v is taken as set.
+func commaOkGuards(ctx passtyps.Context, assert commaOkAssert, guardAt ast.Node) []*passtyps.ParamUsage {
+	var guards []*passtyps.ParamUsage
+	if assert.lookup != nil {
+		guards = nilCompGuard(ctx, guardAt, assert.lookup.X)
+	} else {
+		typ := ctx.Pass.TypesInfo.TypeOf(assert.expr.Type)
+		guards = nilCompGuard(ctx, guardAt, assert.expr.X)
+		for _, guard := range guards {
+			guard.AssertedTyp = typ
+		}
+	}
+	if assert.value != nil && common.IsTargetedParam(ctx, assert.value) != nil {
+		guards = append(guards, passtyps.NewParamUsage(ctx.Pass.TypesInfo.ObjectOf(assert.value), guardAt, nil, assert.value.Pos()))
+	}
+	return guards
+}
+
 func runSelectorExprTree(ctx passtyps.Context, expr ast.Expr, use bool) []*passtyps.ParamUsage {
 	children := common.GetSelectorExprChildren(expr)
 	if len(children) == 0 {
@@ -272,44 +532,45 @@ func runSelectorExprTree(ctx passtyps.Context, expr ast.Expr, use bool) []*passt
 	}
 
 	mostParentExpr := children[len(children)-1].X
-	lastChildIdent := children[0].Sel
-
+	v := common.IsTargetedParam(ctx, mostParentExpr)
+	if v == nil {
+		return nil
+	}
+	start := 0
+	if use {
+		// Skip the first child, which is the last property (e.g., a.b.c.last)
+		start = 1
+	} else {
+		// Only the last property is guarded
+		children = children[:1]
+	}
 	var usages []*passtyps.ParamUsage
-	if v := common.IsTargetedParam(ctx, mostParentExpr); v != nil {
-		mostParentIdent := common.Cast2Ident(mostParentExpr)
-		mostParentTyp := common.UnwrapPtrTyp(ctx.Pass.TypesInfo.Uses[mostParentIdent].Type())
-		for typ, innerChildren := range ctx.TypCollection {
-			if typ == mostParentTyp {
-				for ident, typ := range innerChildren {
-					switch typ.Type().Underlying().(type) {
-					case *types.Pointer, *types.Interface:
-						start := 0
-						if use {
-							// Skip the first child, which is the last property (e.g., a.b.c.last)
-							start = 1
-						}
-						for i := start; i < len(children); i++ {
-							if children[i].Sel.Name == ident.Name {
-								usage := ctx.Pass.TypesInfo.Uses[children[i].Sel]
-								if usage.Type() == typ.Type() {
-									if use {
-										paramUsage := passtyps.NewParamUsage(usage, nil, expr, v.Pos())
-										paramUsage.Context = v
-										usages = append(usages, paramUsage)
-									} else {
-										if lastChildIdent.Name == ident.Name {
-											paramUsage := passtyps.NewParamUsage(usage, expr, nil, v.Pos())
-											paramUsage.Context = v
-											usages = append(usages, paramUsage)
-										}
-									}
-								}
-							}
-						}
-					}
-				}
-			}
+	for i := start; i < len(children); i++ {
+		member := paramMember(ctx, children[i].Sel)
+		if member == nil {
+			continue
+		}
+		if kind := kindOf(member.Type()); kind != kindPointer && kind != kindInterface {
+			continue
 		}
+		paramUsage := passtyps.NewParamUsage(member, nil, nil, v.Pos())
+		if use {
+			paramUsage.UseAt = expr
+			_, paramUsage.Path = common.MemberPath(ctx.Pass.TypesInfo, children[i])
+			paramUsage.Op = opKind(opField, kindOf(member.Type()))
+		} else {
+			paramUsage.GuardAt = expr
+		}
+		paramUsage.Context = v
+		usages = append(usages, paramUsage)
 	}
 	return usages
 }
+
+// paramMember returns the field selected by `sel` if it is reachable from the parameters
+func paramMember(ctx passtyps.Context, sel *ast.Ident) *types.Var {
+	if field, ok := ctx.Pass.TypesInfo.Uses[sel].(*types.Var); ok && field.IsField() && ctx.Members[field] {
+		return field
+	}
+	return nil
+}
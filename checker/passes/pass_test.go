@@ -5,6 +5,7 @@ import (
 
 	"github.com/hyunsooda/paramguard/checker/passes"
 	"github.com/hyunsooda/paramguard/checker/passtyps"
+	"github.com/hyunsooda/paramguard/checker/report"
 	"golang.org/x/tools/go/analysis/analysistest"
 )
 
@@ -13,6 +14,88 @@ func TestChecker(t *testing.T) {
 	passtyps.InitTest()
 	passes.Init()
 
-	tcs := []string{"slice", "pointer", "map", "interface", "struct"}
+	tcs := []string{"slice", "pointer", "map", "interface", "struct", "branch", "earlyexit", "summary", "confirmed", "alias", "reassign", "funclit", "bounds", "assert", "channel", "generics", "members", "embedded", "typednil", "callpaths"}
 	analysistest.Run(t, testdata, passes.MainAnalyzer, tcs...)
 }
+
+func TestCallers(t *testing.T) {
+	testdata := analysistest.TestData()
+	passes.Init()
+
+	tcs := map[string]string{
+		passtyps.CALLERS_DROP:      "callers/drop",
+		passtyps.CALLERS_DOWNGRADE: "callers/downgrade",
+	}
+	for mode, tc := range tcs {
+		passtyps.InitTest()
+		passtyps.Testing.Config = &passtyps.Config{Callers: mode}
+		analysistest.Run(t, testdata, passes.MainAnalyzer, tc)
+	}
+}
+
+func TestReceivers(t *testing.T) {
+	testdata := analysistest.TestData()
+	passtyps.InitTest()
+	passes.Init()
+	passtyps.Testing.Config = &passtyps.Config{Receivers: true, NilSafe: []string{"receiver.Getter"}}
+
+	analysistest.Run(t, testdata, passes.MainAnalyzer, "receiver")
+}
+
+func TestElements(t *testing.T) {
+	testdata := analysistest.TestData()
+	passtyps.InitTest()
+	passes.Init()
+	passtyps.Testing.Config = &passtyps.Config{Elements: true}
+
+	analysistest.Run(t, testdata, passes.MainAnalyzer, "elements")
+}
+
+func TestResults(t *testing.T) {
+	testdata := analysistest.TestData()
+	passtyps.InitTest()
+	passes.Init()
+	passtyps.Testing.Config = &passtyps.Config{NilReturns: []string{"results.Cache.Get"}}
+
+	analysistest.Run(t, testdata, passes.MainAnalyzer, "results")
+}
+
+func TestFindings(t *testing.T) {
+	testdata := analysistest.TestData()
+	passtyps.InitTest()
+	passes.Init()
+
+	for _, result := range analysistest.Run(t, testdata, passes.MainAnalyzer, "pointer", "members") {
+		for _, diag := range result.Diagnostics {
+			pos := result.Pass.Fset.Position(diag.Pos)
+			if diag.Category == "" || diag.End <= diag.Pos {
+				t.Errorf("%s: %q is not classified or has no extent", pos, diag.Message)
+			}
+			if len(diag.Related) != 1 || diag.Related[0].Pos >= diag.Pos {
+				t.Errorf("%s: %q is not related to the declaration of its parameter", pos, diag.Message)
+			}
+		}
+	}
+}
+
+func TestConfirmedGuards(t *testing.T) {
+	testdata := analysistest.TestData()
+	passtyps.InitTest()
+	passes.Init()
+
+	expected := map[string]string{
+		"confirmed.deref": "p != nil",
+		"confirmed.index": "len(b) > 0",
+	}
+	for _, result := range analysistest.Run(t, testdata, passes.MainAnalyzer, "confirmed") {
+		for _, f := range result.Result.([]*report.Finding) {
+			guard, ok := expected[f.Func]
+			if !f.Confirmed || !ok {
+				continue
+			}
+			if len(f.Guards) != 1 || f.Guards[0] != guard {
+				t.Errorf("%s: guards of %q are %v, expected [%s]", f.Pos, f.Message(), f.Guards, guard)
+			}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package passes
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/hyunsooda/paramguard/checker/common"
+	"github.com/hyunsooda/paramguard/checker/passtyps"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// callResults returns the locals initialized from the nilable results of calls, which are checked as parameters:
+// the non-error results of `v, err := f()`, non-nil once `err` is checked, and the results of the functions
+// configured to return nil when not found, e.g., `v := cache.Get(k)`.
+// The results of the former are mapped from their call, to be guarded by the error check.
+func callResults(ctx passtyps.Context, body *ast.BlockStmt, config *passtyps.Config, summaries func(*types.Func) *passtyps.GuardFact) ([]types.Object, map[*ast.CallExpr][]types.Object) {
+	info := ctx.Pass.TypesInfo
+	var values []types.Object
+	errChecked := make(map[*ast.CallExpr][]types.Object)
+	track := func(lhs []*ast.Ident, rhs []ast.Expr) {
+		if len(rhs) != 1 {
+			return
+		}
+		callExpr, ok := unparen(rhs[0]).(*ast.CallExpr)
+		if !ok {
+			return
+		}
+		fn, ok := typeutil.Callee(info, callExpr).(*types.Func)
+		if !ok {
+			return
+		}
+		results := fn.Type().(*types.Signature).Results()
+		if results.Len() != len(lhs) {
+			return
+		}
+		returnsNil := passtyps.ReturnsNil(config, fn)
+		returnsErr := results.Len() > 1 && common.IsErrorTyp(results.At(results.Len()-1).Type())
+		if !returnsNil && !returnsErr {
+			return
+		}
+		var nonNil []int
+		if summary := summaries(fn); summary != nil {
+			nonNil = summary.NonNilResults
+		}
+		for i, ident := range lhs {
+			obj := info.Defs[ident]
+			if obj == nil || !common.IsNilableTyp(obj.Type()) || common.IsErrorTyp(obj.Type()) || containsInt(nonNil, i) {
+				continue
+			}
+			values = append(values, obj)
+			if !returnsNil {
+				errChecked[callExpr] = append(errChecked[callExpr], obj)
+			}
+		}
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch stmt := n.(type) {
+		case *ast.FuncLit:
+			return false // analyzed on its own
+		case *ast.AssignStmt:
+			if stmt.Tok != token.DEFINE {
+				break
+			}
+			lhs := make([]*ast.Ident, len(stmt.Lhs))
+			for i, expr := range stmt.Lhs {
+				lhs[i], _ = expr.(*ast.Ident)
+			}
+			track(lhs, stmt.Rhs)
+		case *ast.ValueSpec:
+			track(stmt.Names, stmt.Values)
+		}
+		return true
+	})
+	return values, errChecked
+}
+
+func containsInt(ns []int, n int) bool {
+	for _, m := range ns {
+		if m == n {
+			return true
+		}
+	}
+	return false
+}
+
+// resultGuards returns the guards on the results of `callExpr` established when its error is nil
+func (fg *flowGraph) resultGuards(ctx passtyps.Context, callExpr *ast.CallExpr, guardAt ast.Node) []*passtyps.ParamUsage {
+	var guards []*passtyps.ParamUsage
+	for _, result := range fg.locals.errResults[callExpr] {
+		guards = append(guards, passtyps.NewParamUsage(result, guardAt, nil, result.Pos()))
+	}
+	return guards
+}
@@ -0,0 +1,296 @@
+package passes
+
+import (
+	"go/ast"
+	"go/types"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hyunsooda/paramguard/checker/common"
+	"github.com/hyunsooda/paramguard/checker/passtyps"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/ctrlflow"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+var SummaryCollector = &analysis.Analyzer{
+	Doc:        "Assistant pass for ParamGuard analyzer",
+	Name:       "summarycollector",
+	Run:        runSummaryCollector,
+	Requires:   []*analysis.Analyzer{inspect.Analyzer, ctrlflow.Analyzer},
+	ResultType: reflect.TypeOf(new(passtyps.GuardSummaries)),
+	FactTypes:  []analysis.Fact{new(passtyps.GuardFact)},
+}
+
+type summarizer struct {
+	pass      *analysis.Pass
+	cfgs      *ctrlflow.CFGs
+	decls     map[*types.Func]*ast.FuncDecl
+	started   map[*types.Func]bool
+	summaries passtyps.GuardSummaries
+}
+
+func runSummaryCollector(pass *analysis.Pass) (interface{}, error) {
+	s := &summarizer{
+		pass:      pass,
+		cfgs:      pass.ResultOf[ctrlflow.Analyzer].(*ctrlflow.CFGs),
+		decls:     make(map[*types.Func]*ast.FuncDecl),
+		started:   make(map[*types.Func]bool),
+		summaries: make(passtyps.GuardSummaries),
+	}
+
+	// Summaries of the functions declared in the other packages
+	for _, obj := range pass.TypesInfo.Uses {
+		if fn, ok := obj.(*types.Func); ok && fn.Pkg() != nil && fn.Pkg() != pass.Pkg {
+			summary := new(passtyps.GuardFact)
+			if pass.ImportObjectFact(fn.Origin(), summary) {
+				s.summaries[fn.Origin()] = summary
+			}
+		}
+	}
+
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	filterNodes := []ast.Node{
+		(*ast.FuncDecl)(nil),
+	}
+	var fns []*types.Func
+	insp.Preorder(filterNodes, func(n ast.Node) {
+		if fnDecl, ok := n.(*ast.FuncDecl); ok {
+			if fn, ok := pass.TypesInfo.Defs[fnDecl.Name].(*types.Func); ok {
+				s.decls[fn] = fnDecl
+				fns = append(fns, fn)
+			}
+		}
+	})
+	for _, fn := range fns {
+		s.lookup(fn)
+	}
+	return &s.summaries, nil
+}
+
+func lookupSummary(summaries passtyps.GuardSummaries) func(*types.Func) *passtyps.GuardFact {
+	return func(fn *types.Func) *passtyps.GuardFact {
+		return summaries[fn.Origin()]
+	}
+}
+
+// lookup summarizes the function on demand, so that the validators called by a validator are summarized first.
+// Recursive calls see no summary.
+func (s *summarizer) lookup(fn *types.Func) *passtyps.GuardFact {
+	fn = fn.Origin()
+	if summary, ok := s.summaries[fn]; ok {
+		return summary
+	}
+	fnDecl, ok := s.decls[fn]
+	if !ok || s.started[fn] {
+		return nil
+	}
+	s.started[fn] = true
+
+	summary := s.summarize(fn, fnDecl)
+	s.summaries[fn] = summary
+	if summary != nil {
+		s.pass.ExportObjectFact(fn, summary)
+	}
+	return summary
+}
+
+func (s *summarizer) summarize(fn *types.Func, fnDecl *ast.FuncDecl) *passtyps.GuardFact {
+	g := s.cfgs.FuncDecl(fnDecl)
+	if g == nil {
+		return nil
+	}
+	sig := fn.Type().(*types.Signature)
+	params := getNilableParams(sig.Params())
+	if sig.Recv() != nil {
+		params = append(params, getNilableParams(types.NewTuple(sig.Recv()))...)
+	}
+	results := sig.Results()
+	nonNilResults := make([]bool, results.Len())
+	for i := range nonNilResults {
+		nonNilResults[i] = common.IsNilableTyp(results.At(i).Type())
+	}
+	if len(params) == 0 && !hasTrue(nonNilResults) {
+		return nil
+	}
+
+	ctx := passtyps.NewContext(s.pass, params, getMembers(params))
+	ctx.Aliases = paramAliases(ctx, fnDecl.Body)
+	fg := newFlowGraph(ctx, g, fnDecl.Body, s.lookup)
+	in := fg.solve(ctx)
+	returnsErr := sig.Results().Len() > 0 && common.IsErrorTyp(sig.Results().At(sig.Results().Len()-1).Type())
+
+	var onReturn, onNilErr guardSet
+	unguarded := make(map[int]passtyps.UnguardedParam)
+	returns := 0
+	for _, blk := range g.Blocks {
+		state, reached := in[blk]
+		if !blk.Live || !reached {
+			continue
+		}
+		fg.walk(ctx, blk, state, func(n ast.Node, state guardSet) {
+			if fn.Exported() {
+				s.addUnguarded(unguarded, sig, fg.unguardedUses(ctx, n, state))
+			}
+			returnStmt, ok := n.(*ast.ReturnStmt)
+			if !ok {
+				return
+			}
+			returns++
+			for i := range nonNilResults {
+				// a bare return, or returning the results of another call, is not followed
+				nonNilResults[i] = nonNilResults[i] && len(returnStmt.Results) == len(nonNilResults) &&
+					fg.isNonNilValue(ctx, returnStmt.Results[i], state)
+			}
+			onReturn = meetReturn(onReturn, state)
+			if !returnsErr || !returnsFailure(ctx, returnStmt, state) {
+				onNilErr = meetReturn(onNilErr, state.with(fg.delegatedGuards(ctx, returnStmt)))
+			}
+		})
+	}
+
+	summary := &passtyps.GuardFact{
+		Guards:       guardedParams(sig, onReturn),
+		NilErrGuards: guardedParams(sig, onNilErr),
+	}
+	for _, param := range unguarded {
+		summary.Unguarded = append(summary.Unguarded, param)
+	}
+	for i, nonNil := range nonNilResults {
+		if nonNil && returns > 0 {
+			summary.NonNilResults = append(summary.NonNilResults, i)
+		}
+	}
+	sort.Slice(summary.Unguarded, func(i, j int) bool {
+		return summary.Unguarded[i].Index < summary.Unguarded[j].Index
+	})
+	if len(summary.Guards) == 0 && len(summary.NilErrGuards) == 0 && len(summary.Unguarded) == 0 && len(summary.NonNilResults) == 0 {
+		return nil
+	}
+	return summary
+}
+
+// addUnguarded keeps the first unguarded use of each parameter, which the callers in the other packages
+// are confirmed against when they pass nil
+func (s *summarizer) addUnguarded(unguarded map[int]passtyps.UnguardedParam, sig *types.Signature, uses []*passtyps.ParamUsage) {
+	for _, use := range uses {
+		if use.Context != nil {
+			continue
+		}
+		index, ok := paramIndex(sig, use.Param)
+		if !ok || index < 0 {
+			continue
+		}
+		if _, ok := unguarded[index]; ok {
+			continue
+		}
+		unguarded[index] = passtyps.UnguardedParam{
+			Index: index,
+			Name:  use.Param.Name(),
+			UseAt: s.pass.Fset.Position(use.UseAt.Pos()),
+			Op:    use.Op,
+		}
+	}
+}
+
+func hasTrue(bs []bool) bool {
+	for _, b := range bs {
+		if b {
+			return true
+		}
+	}
+	return false
+}
+
+func meetReturn(guards, state guardSet) guardSet {
+	if guards == nil {
+		return state
+	}
+	return guards.meet(state)
+}
+
+// delegatedGuards returns the guards of a validator whose error is returned as is, e.g., `return validate(p)`
+func (fg *flowGraph) delegatedGuards(ctx passtyps.Context, returnStmt *ast.ReturnStmt) []*passtyps.ParamUsage {
+	if len(returnStmt.Results) == 0 {
+		return nil
+	}
+	callExpr, ok := returnStmt.Results[len(returnStmt.Results)-1].(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	if fn, summary := fg.summary(ctx, callExpr); summary != nil {
+		return guardsForCall(ctx, callExpr, fn, summary.NilErrGuards)
+	}
+	return nil
+}
+
+// returnsFailure reports whether the error returned by `returnStmt` is definitely non-nil
+func returnsFailure(ctx passtyps.Context, returnStmt *ast.ReturnStmt, state guardSet) bool {
+	if len(returnStmt.Results) == 0 {
+		return false
+	}
+	switch expr := returnStmt.Results[len(returnStmt.Results)-1].(type) {
+	case *ast.UnaryExpr, *ast.CompositeLit: // e.g., &MyErr{}
+		return true
+	case *ast.CallExpr: // e.g., errors.New("nil p")
+		if fn := typeutil.StaticCallee(ctx.Pass.TypesInfo, expr); fn != nil && fn.Pkg() != nil {
+			path, name := fn.Pkg().Path(), fn.Name()
+			return path == "errors" && name == "New" || path == "fmt" && name == "Errorf"
+		}
+	case *ast.Ident: // e.g., errNilP, or err after `if err != nil`
+		obj := ctx.Pass.TypesInfo.ObjectOf(expr)
+		if obj == nil || obj.Pkg() == nil {
+			return false
+		}
+		return obj.Parent() == obj.Pkg().Scope() || state[guardID{param: obj}] != nil
+	}
+	return false
+}
+
+// guardedParams converts the guards on the parameters of `sig` into their positions
+func guardedParams(sig *types.Signature, guards guardSet) []passtyps.GuardedParam {
+	var guarded []passtyps.GuardedParam
+	for _, guard := range guards {
+		if guard.IsNil || guard.Reassigned {
+			continue
+		}
+		root := guard.Param
+		if guard.Context != nil {
+			if len(guard.Path) == 0 {
+				continue
+			}
+			root = guard.Context
+		}
+		index, ok := paramIndex(sig, root)
+		if !ok {
+			continue
+		}
+		var members []string
+		for _, member := range guard.Path {
+			members = append(members, member.Name())
+		}
+		guarded = append(guarded, passtyps.GuardedParam{Index: index, Members: members, MinLen: guard.MinLen})
+	}
+	sort.Slice(guarded, func(i, j int) bool {
+		if guarded[i].Index != guarded[j].Index {
+			return guarded[i].Index < guarded[j].Index
+		}
+		return strings.Join(guarded[i].Members, ".") < strings.Join(guarded[j].Members, ".")
+	})
+	return guarded
+}
+
+func paramIndex(sig *types.Signature, param types.Object) (int, bool) {
+	if sig.Recv() == param {
+		return -1, true
+	}
+	for i := 0; i < sig.Params().Len(); i++ {
+		if sig.Params().At(i) == param {
+			return i, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,43 @@
+package alias
+
+import "fmt"
+
+func _(a, b *int) {
+	if a != nil {
+		fmt.Println(*a, *b) // want "Unsafely used 'b'"
+	}
+}
+
+func _(a, b []int) {
+	if len(b) > 0 {
+		fmt.Println(a[0], b[0]) // want "Unsafely used 'a'"
+	}
+}
+
+func _(p *int) {
+	q := p
+	fmt.Println(*q) // want "Unsafely used 'p'"
+}
+
+func _(p *int) {
+	var q = p
+	r := q
+	fmt.Println(*r) // want "Unsafely used 'p'"
+}
+
+func _(p, other *int) {
+	q := p
+	if other != nil {
+		q = other
+	}
+	if q != nil {
+		fmt.Println(*p) // want "Unsafely used 'p'"
+	}
+}
+
+func _(p *int) {
+	if p := new(int); p != nil {
+		fmt.Println(*p)
+	}
+	fmt.Println(*p) // want "Unsafely used 'p'"
+}
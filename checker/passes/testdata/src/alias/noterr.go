@@ -0,0 +1,52 @@
+package alias
+
+import "fmt"
+
+func _(p *int) {
+	q := p
+	if q != nil {
+		fmt.Println(*p, *q)
+	}
+}
+
+func _(p *int) {
+	if p == nil {
+		return
+	}
+	q := p
+	fmt.Println(*q)
+}
+
+func _(p *int) {
+	var q *int
+	fmt.Println(q != nil && *q > 0)
+	q = new(int)
+	fmt.Println(*q)
+}
+
+func _(p *int) {
+	if p != nil {
+		fmt.Println(*p)
+	}
+	{
+		p := new(int)
+		fmt.Println(*p)
+	}
+}
+
+func _(p []int) {
+	for _, p := range [][]int{{1}} {
+		fmt.Println(p[0])
+	}
+}
+
+func _(p *int, cond bool) {
+	q := p
+	if cond {
+		q = p
+	}
+	if q == nil {
+		return
+	}
+	fmt.Println(*p)
+}
@@ -0,0 +1,42 @@
+package assert
+
+import "fmt"
+
+type T struct {
+	n int
+}
+
+func _(p interface{}) {
+	fmt.Println(p.(int)) // want "Unsafely used 'p'"
+}
+
+func _(p interface{}) {
+	if p != nil {
+		fmt.Println(p.(*T)) // want "Unsafely used 'p'"
+	}
+}
+
+func _(p interface{}) {
+	if _, ok := p.(int); ok {
+		fmt.Println(p.(string)) // want "Unsafely used 'p'"
+	}
+}
+
+func _(p interface{}) {
+	v, ok := p.(*T)
+	fmt.Println(ok, v.n) // want "Unsafely used 'v'"
+}
+
+func _(p interface{}) {
+	v, ok := p.(*T)
+	if !ok {
+		fmt.Println(v.n) // want "Unsafely used 'v'"
+	}
+}
+
+func _(p fmt.Stringer) {
+	if _, ok := p.(*T); ok {
+		return
+	}
+	fmt.Println(p.String()) // want "Unsafely used 'p'"
+}
@@ -0,0 +1,46 @@
+package assert
+
+import "fmt"
+
+func (t *T) String() string {
+	return fmt.Sprint(t.n)
+}
+
+func _(p interface{}) {
+	v, ok := p.(*T)
+	if ok {
+		fmt.Println(v.n)
+	}
+}
+
+func _(p interface{}) {
+	if v, ok := p.(*T); ok {
+		fmt.Println(v.n, p.(*T))
+	}
+}
+
+func _(p interface{}) {
+	v, ok := p.(*T)
+	if !ok {
+		return
+	}
+	fmt.Println(v.n)
+}
+
+func _(p fmt.Stringer) {
+	if _, ok := p.(*T); ok {
+		fmt.Println(p.String())
+	}
+}
+
+func _(p interface{}) {
+	switch p.(type) {
+	case int:
+		fmt.Println(p.(int))
+	}
+}
+
+func _(p interface{}) {
+	n, ok := p.(int)
+	fmt.Println(n, ok)
+}
@@ -0,0 +1,83 @@
+package bounds
+
+import "fmt"
+
+func _(b []byte) {
+	if len(b) > 0 {
+		fmt.Println(b[5]) // want "Possible index out of range 'b'"
+	}
+}
+
+func _(b []byte) {
+	if len(b) < 3 {
+		return
+	}
+	fmt.Println(b[2], b[3]) // want "Possible index out of range 'b'"
+}
+
+func _(b []byte, i int) {
+	if len(b) != 0 {
+		fmt.Println(b[i]) // want "Possible index out of range 'b'"
+	}
+}
+
+func _(b []byte) {
+	if len(b) >= 2 {
+		fmt.Println(b[:4]) // want "Possible index out of range 'b'"
+	}
+}
+
+func _(b []byte) {
+	if len(b) > 0 {
+		fmt.Println(b[len(b)-2]) // want "Possible index out of range 'b'"
+	}
+}
+
+func _(b []byte, cond bool) {
+	if cond {
+		if len(b) < 4 {
+			return
+		}
+	} else if len(b) == 0 {
+		return
+	}
+	fmt.Println(b[3]) // want "Possible index out of range 'b'"
+}
+
+func _(b []byte) {
+	for i := 0; i < len(b); i++ {
+		i++
+		fmt.Println(b[i]) // want "Possible index out of range 'b'"
+	}
+}
+
+func _(b []byte) {
+	if b != nil {
+		fmt.Println(b[5]) // want "Possible index out of range 'b'"
+	}
+}
+
+func _(b []byte) {
+	if b == nil {
+		return
+	}
+	fmt.Println(b[0])  // want "Possible index out of range 'b'"
+	fmt.Println(b[1:]) // want "Possible index out of range 'b'"
+}
+
+func _(b []byte) {
+	b = append(b, 1)
+	fmt.Println(b[1]) // want "Possible index out of range 'b'"
+}
+
+func _(b []byte, n int) {
+	if len(b) >= n {
+		fmt.Println(b[0]) // want "Possible index out of range 'b'"
+	}
+}
+
+func _(b []byte, k int) {
+	if k <= len(b) {
+		fmt.Println(b[k]) // want "Possible index out of range 'b'"
+	}
+}
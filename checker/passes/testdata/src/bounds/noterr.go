@@ -0,0 +1,56 @@
+package bounds
+
+import "fmt"
+
+func _(b []byte) {
+	if len(b) > 5 {
+		fmt.Println(b[5], b[:6], b[2:4])
+	}
+}
+
+func _(b []byte) {
+	if len(b) < 3 {
+		return
+	}
+	fmt.Println(b[0], b[2], b[len(b)-3], b[1:], b[:len(b)])
+}
+
+func _(b []byte) {
+	for i := range b {
+		fmt.Println(b[i])
+	}
+}
+
+func _(b []byte) {
+	for i := 0; i < len(b); i++ {
+		fmt.Println(b[i])
+	}
+}
+
+func _(b []byte, i int) {
+	if i < len(b) {
+		fmt.Println(b[i], b[i:], b[:i])
+	}
+}
+
+func _(b []byte) {
+	if len(b) > 0 {
+		if len(b) >= 4 {
+			fmt.Println(b[3])
+		}
+		fmt.Println(b[len(b)-1])
+	}
+}
+
+func _(b []byte, k int) {
+	if len(b) >= k {
+		fmt.Println(b[:k], b[k-1])
+	}
+}
+
+func _(b []byte, k int) {
+	if len(b) < k {
+		return
+	}
+	fmt.Println(b[k-1:])
+}
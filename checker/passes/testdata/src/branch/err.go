@@ -0,0 +1,70 @@
+package branch
+
+import "fmt"
+
+type Itf interface {
+	Get() int
+}
+
+func _(p *int) {
+	if p == nil {
+		fmt.Println(*p) // want "Unsafely used 'p'"
+	}
+}
+
+func _(p *int) {
+	if p != nil {
+		fmt.Println(*p)
+	} else {
+		fmt.Println(*p) // want "Unsafely used 'p'"
+	}
+}
+
+func _(p *int) {
+	if p != nil {
+		fmt.Println(*p)
+	}
+	fmt.Println(*p) // want "Unsafely used 'p'"
+}
+
+func _(b []byte) {
+	if len(b) <= 0 {
+		fmt.Println(b[0]) // want "Unsafely used 'b'"
+	}
+}
+
+type opts struct {
+	n int
+}
+
+func _(b []byte, o opts) {
+	if len(b) >= o.n {
+		fmt.Println(b[0]) // want "Unsafely used 'b'"
+	}
+}
+
+func _(b []byte, o opts) {
+	if o.n <= len(b) {
+		fmt.Println(b[0]) // want "Unsafely used 'b'"
+	}
+}
+
+func _(p *int) {
+	switch p {
+	case nil:
+		fmt.Println(*p) // want "Unsafely used 'p'"
+	}
+}
+
+func _(i Itf) int {
+	switch i.(type) {
+	case Itf:
+		return 0
+	default:
+		return i.Get() // want "Unsafely used 'i'"
+	}
+}
+
+func _(p *int) bool {
+	return p == nil && *p == 0 // want "Unsafely used 'p'"
+}
@@ -0,0 +1,62 @@
+package branch
+
+import "fmt"
+
+func _(p *int) {
+	if !(p == nil) {
+		fmt.Println(*p)
+	}
+}
+
+func _(p *int) {
+	if p == nil {
+		fmt.Println("nil")
+	} else {
+		fmt.Println(*p)
+	}
+}
+
+func _(p *int) {
+	switch {
+	case p != nil:
+		fmt.Println(*p)
+	}
+}
+
+func _(p *int) {
+	switch p {
+	case nil:
+	default:
+		fmt.Println(*p)
+	}
+}
+
+func _(i Itf) int {
+	switch v := i.(type) {
+	case nil:
+		return 0
+	default:
+		fmt.Println(v)
+		return i.Get()
+	}
+}
+
+func _(p *int) {
+	for p != nil {
+		fmt.Println(*p)
+	}
+}
+
+func _(p *int) bool {
+	return p != nil && *p == 0
+}
+
+func _(p *int) bool {
+	return p == nil || *p == 0
+}
+
+func _(b []byte) {
+	if 0 < len(b) {
+		fmt.Println(b[0])
+	}
+}
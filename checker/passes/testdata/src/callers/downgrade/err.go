@@ -0,0 +1,18 @@
+package downgrade
+
+import "fmt"
+
+func deref(p *int) {
+	fmt.Println(*p) // want `Unsafely used 'p' \(non-nil at all 2 call sites\)`
+}
+
+func mayNil(p *int) {
+	fmt.Println(*p) // want `Unsafely used 'p'$`
+}
+
+func _(p *int) {
+	x := 1
+	deref(&x)
+	deref(new(int))
+	mayNil(nil) // want "Confirmed nil passed to 'p'"
+}
@@ -0,0 +1,35 @@
+package drop
+
+import "fmt"
+
+// Exported functions stay strict
+func Deref(p *int) {
+	fmt.Println(*p) // want "Unsafely used 'p'"
+}
+
+func mayNil(p *int) {
+	fmt.Println(*p) // want "Unsafely used 'p'"
+}
+
+func emptySlice(b []byte) {
+	fmt.Println(b[0]) // want "Unsafely used 'b'"
+}
+
+func escaped(p *int) {
+	fmt.Println(*p) // want "Unsafely used 'p'"
+}
+
+func neverCalled(p *int) {
+	fmt.Println(*p) // want "Unsafely used 'p'"
+}
+
+var callback = escaped
+
+func _(p *int) {
+	x := 1
+	mayNil(&x)
+	mayNil(p)
+	emptySlice([]byte{}) // want "Confirmed nil passed to 'b'"
+	Deref(&x)
+	escaped(&x)
+}
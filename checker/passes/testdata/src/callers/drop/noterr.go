@@ -0,0 +1,45 @@
+package drop
+
+import "fmt"
+
+type T struct {
+	n int
+}
+
+func deref(p *int) {
+	fmt.Println(*p)
+}
+
+func member(t *T) {
+	fmt.Println(t.n)
+}
+
+func index(b []byte) {
+	fmt.Println(b[0])
+}
+
+func write(m map[string]int) {
+	m["k"] = 1
+}
+
+func call(f func()) {
+	f()
+}
+
+func _(p *int) {
+	x := 1
+	deref(&x)
+	deref(new(int))
+	if p != nil {
+		deref(p)
+	}
+	member(&T{})
+	index([]byte{1})
+	index(make([]byte, 4))
+	write(map[string]int{})
+	write(make(map[string]int))
+	call(func() {})
+	call(noop)
+}
+
+func noop() {}
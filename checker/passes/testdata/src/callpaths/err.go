@@ -0,0 +1,34 @@
+package callpaths
+
+import "fmt"
+
+func use(p *int) {
+	fmt.Println(*p) // want "Unsafely used 'p'"
+}
+
+func a(p *int) {
+	use(p)
+}
+
+func b(p *int) {
+	use(p)
+}
+
+func c() {
+	n := 1
+	b(&n)
+}
+
+func recursive(p *int, n int) {
+	if n > 0 {
+		recursive(p, n-1)
+	}
+	use(p)
+}
+
+func main() {
+	n := 1
+	a(&n)
+	b(&n)
+	recursive(&n, 1)
+}
@@ -0,0 +1,41 @@
+package channel
+
+import "fmt"
+
+type Pool struct {
+	jobs chan int
+	done chan struct{}
+}
+
+func _(ch chan int) {
+	ch <- 1 // want "Unsafely used 'ch'"
+}
+
+func _(ch <-chan int) {
+	fmt.Println(<-ch) // want "Unsafely used 'ch'"
+}
+
+func _(ch chan int) {
+	v, ok := <-ch // want "Unsafely used 'ch'"
+	fmt.Println(v, ok)
+}
+
+func _(ch chan int) {
+	close(ch) // want "Unsafely used 'ch'"
+}
+
+func _(ch chan int) {
+	for v := range ch { // want "Unsafely used 'ch'"
+		fmt.Println(v)
+	}
+}
+
+func _(ch chan int) {
+	for range ch { // want "Unsafely used 'ch'"
+	}
+}
+
+func _(p Pool) {
+	p.jobs <- 1   // want "Unsafely used 'jobs'"
+	close(p.done) // want "Unsafely used 'done'"
+}
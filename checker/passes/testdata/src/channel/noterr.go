@@ -0,0 +1,43 @@
+package channel
+
+import "fmt"
+
+func _(ch chan int) {
+	if ch != nil {
+		ch <- 1
+		fmt.Println(<-ch, len(ch), cap(ch))
+		close(ch)
+	}
+}
+
+func _(ch chan int) {
+	if ch == nil {
+		return
+	}
+	for v := range ch {
+		fmt.Println(v)
+	}
+}
+
+// A nil channel disables its select case
+func _(in <-chan int, out chan<- int, quit chan struct{}) {
+	for {
+		select {
+		case v, ok := <-in:
+			fmt.Println(v, ok)
+		case out <- 1:
+		case <-quit:
+			return
+		}
+	}
+}
+
+func _(p Pool) {
+	if p.done != nil {
+		close(p.done)
+	}
+	select {
+	case p.jobs <- 1:
+	default:
+	}
+}
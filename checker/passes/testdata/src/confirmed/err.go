@@ -0,0 +1,56 @@
+package confirmed
+
+import (
+	"fmt"
+
+	"validator"
+)
+
+type T struct {
+	n int
+}
+
+func deref(p *int) {
+	fmt.Println(*p) // want "Unsafely used 'p'"
+}
+
+func index(b []byte) {
+	fmt.Println(b[0]) // want "Unsafely used 'b'"
+}
+
+func member(t *T) {
+	fmt.Println(t.n) // want "Unsafely used 't'"
+}
+
+func _() {
+	deref(nil)         // want "Confirmed nil passed to 'p'"
+	deref((*int)(nil)) // want "Confirmed nil passed to 'p'"
+	index([]byte{})    // want "Confirmed nil passed to 'b'"
+	index(nil)         // want "Confirmed nil passed to 'b'"
+}
+
+func _() {
+	var p *int
+	deref(p) // want "Confirmed nil passed to 'p'"
+	var t *T
+	member(t) // want "Confirmed nil passed to 't'"
+	q := (*int)(nil)
+	deref(q) // want "Confirmed nil passed to 'p'"
+}
+
+func _(p *int) {
+	if p == nil {
+		deref(p) // want "Confirmed nil passed to 'p'"
+	}
+}
+
+func _(p *int) {
+	if p != nil {
+		return
+	}
+	deref(p) // want "Confirmed nil passed to 'p'"
+}
+
+func _() {
+	validator.DBName(nil) // want "Confirmed nil passed to 'cfg'"
+}
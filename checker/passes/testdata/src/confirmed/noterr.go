@@ -0,0 +1,68 @@
+package confirmed
+
+import (
+	"fmt"
+
+	"validator"
+)
+
+func guarded(p *int) {
+	if p != nil {
+		fmt.Println(*p)
+	}
+}
+
+func _() {
+	guarded(nil)
+	x := 1
+	deref(&x)
+	index([]byte{1})
+}
+
+func _(cond bool) {
+	var p *int
+	if cond {
+		x := 1
+		p = &x
+	}
+	deref(p)
+}
+
+func _() {
+	p := (*int)(nil)
+	p = new(int)
+	deref(p)
+}
+
+func _() {
+	var p *int
+	set := func() {
+		x := 1
+		p = &x
+	}
+	set()
+	deref(p)
+}
+
+func _() {
+	var p *int
+	init := func(pp **int) {
+		if pp != nil {
+			x := 1
+			*pp = &x
+		}
+	}
+	init(&p)
+	deref(p)
+}
+
+func _(p *int) {
+	if p == nil {
+		p = new(int)
+	}
+	deref(p)
+}
+
+func _() {
+	validator.Validate(nil)
+}
@@ -0,0 +1,35 @@
+package earlyexit
+
+import "fmt"
+
+func _(a *int, b []byte) {
+	if a == nil && len(b) == 0 {
+		return
+	}
+	fmt.Println(*a, b[0]) // want "Unsafely used 'a'" "Unsafely used 'b'"
+}
+
+func _(p *int) {
+	if p == nil {
+		fmt.Println("nil p")
+	}
+	fmt.Println(*p) // want "Unsafely used 'p'"
+}
+
+func _(p *int) {
+	for {
+		if p == nil {
+			break
+		}
+		fmt.Println(*p)
+	}
+	fmt.Println(*p) // want "Unsafely used 'p'"
+}
+
+func _(a *int, b *int) {
+	if a == nil || b != nil {
+		fmt.Println(*b) // want "Unsafely used 'b'"
+		return
+	}
+	fmt.Println(*a)
+}
@@ -0,0 +1,93 @@
+package earlyexit
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"testing"
+)
+
+var errNilP = errors.New("nil p")
+
+func _(p *int) (*int, error) {
+	if p == nil {
+		return nil, errNilP
+	}
+	fmt.Println(*p)
+	return p, nil
+}
+
+func _(p *int) {
+	if p == nil {
+		panic("nil p")
+	}
+	fmt.Println(*p)
+}
+
+func _(p *int) {
+	if p == nil {
+		log.Fatal("nil p")
+	}
+	fmt.Println(*p)
+}
+
+func _(p *int) {
+	if p == nil {
+		log.Fatalf("nil %s", "p")
+	}
+	fmt.Println(*p)
+}
+
+func _(p *int) {
+	if p == nil {
+		os.Exit(1)
+	}
+	fmt.Println(*p)
+}
+
+func _(t *testing.T, p *int) {
+	if p == nil {
+		t.Fatal("nil p")
+	}
+	fmt.Println(*p)
+}
+
+func _(tb testing.TB, p *int) {
+	if p == nil {
+		tb.Fatalf("nil p")
+	}
+	fmt.Println(*p)
+}
+
+func _(a *int, b []byte) {
+	if a == nil || len(b) == 0 {
+		return
+	}
+	fmt.Println(*a, b[0])
+}
+
+func _(a *int, b *int) {
+	if !(a != nil && b != nil) {
+		return
+	}
+	fmt.Println(*a, *b)
+}
+
+func _(n int, p *int) {
+	for i := 0; i < n; i++ {
+		if p == nil {
+			continue
+		}
+		fmt.Println(*p)
+	}
+}
+
+func _(p *int) {
+	for {
+		if p == nil {
+			break
+		}
+		fmt.Println(*p)
+	}
+}
@@ -0,0 +1,53 @@
+package elements
+
+import (
+	"fmt"
+	"io"
+)
+
+type Item struct {
+	Name string
+}
+
+type Pool struct {
+	conns map[string]io.Closer
+}
+
+func _(items []*Item) {
+	if len(items) > 0 {
+		fmt.Println(items[0].Name) // want `Unsafely used 'items\[0\]'`
+	}
+}
+
+func _(items []*Item) {
+	for _, it := range items {
+		fmt.Println(it.Name) // want "Unsafely used 'it'"
+	}
+}
+
+func _(m map[string]io.Closer, k string) error {
+	return m[k].Close() // want `Unsafely used 'm\[k\]'`
+}
+
+func _(m map[string]io.Closer, k string) error {
+	c := m[k]
+	return c.Close() // want "Unsafely used 'c'"
+}
+
+func _(p Pool, k string) error {
+	return p.conns[k].Close() // want `Unsafely used 'p.conns\[k\]'`
+}
+
+// The guard on the previous element does not hold after moving the index
+func _(items []*Item, i int) {
+	if i < len(items) && items[i] != nil {
+		i++
+		fmt.Println(items[i].Name) // want `Unsafely used 'items\[i\]'` "Possible index out of range 'items'"
+	}
+}
+
+func _(handlers []func()) {
+	if len(handlers) > 0 {
+		handlers[0]() // want `Unsafely used 'handlers\[0\]'`
+	}
+}
@@ -0,0 +1,51 @@
+package elements
+
+import (
+	"fmt"
+	"io"
+)
+
+func _(items []*Item) {
+	for _, it := range items {
+		if it == nil {
+			continue
+		}
+		fmt.Println(it.Name)
+	}
+}
+
+func _(items []*Item) {
+	for i := range items {
+		if items[i] != nil {
+			fmt.Println(items[i].Name)
+		}
+	}
+}
+
+func _(m map[string]io.Closer, k string) error {
+	if c, ok := m[k]; ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func _(m map[string]io.Closer, k string) error {
+	if m[k] == nil {
+		return nil
+	}
+	return m[k].Close()
+}
+
+func _(items []*Item) {
+	if len(items) > 0 {
+		items[0] = &Item{}
+		fmt.Println(items[0].Name)
+	}
+}
+
+// The elements of non-nilable types are not checked
+func _(names []string, counts map[string]int) {
+	for _, name := range names {
+		fmt.Println(name, counts[name])
+	}
+}
@@ -0,0 +1,49 @@
+package embedded
+
+import "fmt"
+
+type Logger interface {
+	Printf(format string, args ...interface{})
+}
+
+type B struct {
+	x int
+}
+
+func (b B) Value() int {
+	return b.x
+}
+
+func (b *B) Set(x int) {
+	if b != nil {
+		b.x = x
+	}
+}
+
+type A struct {
+	*B
+	Logger
+}
+
+type Outer struct {
+	a A
+}
+
+// a.x dereferences the embedded *B
+func _(a A) {
+	fmt.Println(a.x) // want "Unsafely used 'B'"
+}
+
+// a.Value() dereferences the embedded *B for its value receiver
+func _(a A) int {
+	return a.Value() // want "Unsafely used 'B'"
+}
+
+// a.Printf() calls the method of a nil interface
+func _(a A) {
+	a.Printf("hello") // want "Unsafely used 'Logger'"
+}
+
+func _(o Outer) {
+	fmt.Println(o.a.x) // want "Unsafely used 'B'"
+}
@@ -0,0 +1,28 @@
+package embedded
+
+import "fmt"
+
+func _(a A) {
+	if a.B != nil {
+		fmt.Println(a.x, a.Value())
+	}
+	if a.Logger != nil {
+		a.Printf("hello")
+	}
+}
+
+func _(o Outer) {
+	if o.a.B == nil {
+		return
+	}
+	fmt.Println(o.a.x)
+}
+
+// A pointer receiver is passed without being dereferenced
+func _(a A) {
+	a.Set(1)
+}
+
+func _(b B) int {
+	return b.x + b.Value()
+}
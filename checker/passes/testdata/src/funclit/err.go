@@ -0,0 +1,62 @@
+package funclit
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+type Item struct {
+	n *int
+}
+
+func _() {
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Println(r.URL) // want "Unsafely used 'r'"
+	})
+}
+
+func _(items []*Item) {
+	sort.Slice(items, func(i, j int) bool {
+		return *items[i].n < *items[j].n // want "Unsafely used 'items'" "Unsafely used 'items'"
+	})
+}
+
+func _(p *int) {
+	check := func() {
+		if p == nil {
+			return
+		}
+	}
+	check()
+	fmt.Println(*p) // want "Unsafely used 'p'"
+}
+
+func _(p *int) {
+	go func() {
+		fmt.Println(*p) // want "Unsafely used 'p'"
+	}()
+}
+
+func _(p *int) {
+	if p != nil {
+		return
+	}
+	defer func() {
+		fmt.Println(*p) // want "Unsafely used 'p'"
+	}()
+}
+
+func _() {
+	register(func(cb func(int)) {
+		func() {
+			cb(1) // want "Unsafely used 'cb'"
+		}()
+	})
+}
+
+func register(f func(func(int))) {
+	if f != nil {
+		f(func(int) {})
+	}
+}
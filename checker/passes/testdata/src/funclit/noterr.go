@@ -0,0 +1,51 @@
+package funclit
+
+import (
+	"fmt"
+	"net/http"
+)
+
+func _() {
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r == nil {
+			return
+		}
+		fmt.Println(r.URL)
+	})
+}
+
+func _(p *int) {
+	if p == nil {
+		return
+	}
+	go func() {
+		fmt.Println(*p)
+	}()
+}
+
+func _(p *int) {
+	if p != nil {
+		func() {
+			fmt.Println(*p)
+		}()
+	}
+}
+
+func _(p *int) {
+	func() {
+		if p != nil {
+			fmt.Println(*p)
+		}
+	}()
+}
+
+func _() {
+	register(func(cb func(int)) {
+		if cb == nil {
+			return
+		}
+		func() {
+			cb(1)
+		}()
+	})
+}
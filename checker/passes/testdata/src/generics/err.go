@@ -0,0 +1,31 @@
+package generics
+
+import "fmt"
+
+type Stack[T any] struct {
+	items []T
+}
+
+func _[T any](p *T, f func(T) T) {
+	fmt.Println(f(*p)) // want "Unsafely used 'p'" "Unsafely used 'f'"
+}
+
+func _[M ~map[K]V, K comparable, V any](m M, k K, v V) {
+	m[k] = v // want "Unsafely used 'm'"
+}
+
+func _[S ~[]E, E any](s S) E {
+	return s[0] // want "Unsafely used 's'"
+}
+
+func _[P interface{ *int }](p P) int {
+	return *p // want "Unsafely used 'p'"
+}
+
+func _[C ~chan E, E any](ch C) {
+	close(ch) // want "Unsafely used 'ch'"
+}
+
+func _[T any](s *Stack[T]) {
+	fmt.Println(s.items) // want "Unsafely used 's'"
+}
@@ -0,0 +1,52 @@
+package generics
+
+import "fmt"
+
+// Type parameters whose type set admits non-nil types only are not nilable
+func _[T any](v T) {
+	fmt.Println(v)
+}
+
+func _[N ~int | ~int64](n N) N {
+	return n * 2
+}
+
+func _[T fmt.Stringer](v T) string {
+	return v.String()
+}
+
+func _[T any](p *T, f func(T) T) {
+	if p == nil || f == nil {
+		return
+	}
+	fmt.Println(f(*p))
+}
+
+func _[M ~map[K]V, K comparable, V any](m M, k K) (V, bool) {
+	v, ok := m[k] // reading a nil map is safe
+	for k, v := range m {
+		fmt.Println(k, v)
+	}
+	return v, ok
+}
+
+func _[S ~[]E, E any](s S) (E, bool) {
+	if len(s) > 0 {
+		return s[0], true
+	}
+	var zero E
+	return zero, false
+}
+
+func _[S ~[]E, E any](s S) {
+	for i := range s {
+		fmt.Println(s[i])
+	}
+}
+
+// Every type in the type set is nilable, so `p` can be compared with nil and guarded
+func _[P ~*int | ~*string](p P) {
+	if p != nil {
+		fmt.Println(p)
+	}
+}
@@ -11,10 +11,14 @@ type B struct {
 	itf Itf
 }
 
-func _(i Itf) int { // want "Declared 'i'"
+func _(i Itf) int {
 	return i.Get() // want "Unsafely used 'i'"
 }
 
-func _(a A) int { // want "Declared 'itf'"
+func _(a A) int {
 	return a.b.itf.Get() // want "Unsafely used 'itf'"
 }
+
+func _(i Itf) func() int {
+	return i.Get // want "Unsafely used 'i'"
+}
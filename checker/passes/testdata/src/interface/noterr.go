@@ -31,3 +31,7 @@ func _(a A) int {
 		return 0
 	}
 }
+
+func _(i Itf) bool {
+	return i == nil
+}
@@ -2,6 +2,21 @@ package nilmap
 
 import "fmt"
 
-func _(m map[string]bool) { // want "Declared 'm'"
-	fmt.Println(m["str"]) // want "Unsafely used 'm'"
+func _(m map[string]bool) {
+	m["str"] = true // want "Unsafely used 'm'"
+}
+
+func _(m map[string]int) {
+	m["str"]++ // want "Unsafely used 'm'"
+}
+
+func _(m map[string]int) {
+	m["str"] += 1 // want "Unsafely used 'm'"
+}
+
+func _(m map[string]int) {
+	if m == nil {
+		fmt.Println(len(m))
+		m["str"], m["other"] = 1, 2 // want "Unsafely used 'm'" "Unsafely used 'm'"
+	}
 }
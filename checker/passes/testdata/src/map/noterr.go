@@ -5,5 +5,25 @@ import "fmt"
 func _(m map[string]bool) {
 	if m != nil {
 		fmt.Println(m["str"])
+		m["str"] = true
 	}
 }
+
+// Reading, ranging over, and deleting from a nil map never panic
+func _(m map[string]bool) {
+	fmt.Println(m["str"], len(m))
+	v, ok := m["str"]
+	fmt.Println(v, ok)
+	for k, v := range m {
+		fmt.Println(k, v)
+	}
+	delete(m, "str")
+	clear(m)
+}
+
+func _(m map[string]int) {
+	if m == nil {
+		m = make(map[string]int)
+	}
+	m["str"]++
+}
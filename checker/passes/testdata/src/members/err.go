@@ -0,0 +1,79 @@
+package members
+
+import (
+	"fmt"
+
+	"validator"
+)
+
+// Members of the structs declared in another package
+func _(req validator.Request) {
+	fmt.Println(req.Cfg.DB) // want `Unsafely used 'Cfg' \(member: 'req.Cfg'\)`
+}
+
+// Members of an anonymous struct
+func _(opts struct{ timeout *int }) {
+	fmt.Println(*opts.timeout) // want "Unsafely used 'timeout'"
+}
+
+type Node[T any] struct {
+	next *Node[T]
+	val  T
+}
+
+// Members of an instantiated generic struct
+func _(n Node[int]) {
+	fmt.Println(n.next.val) // want "Unsafely used 'next'"
+}
+
+type Cfg = validator.Config
+
+// Members of an aliased struct
+func _(c Cfg) {
+	fmt.Println(c.DB.Name) // want "Unsafely used 'DB'"
+}
+
+type Left struct {
+	x *int
+}
+
+type Right struct {
+	x *int
+}
+
+// A guard on a member never sanitizes the same-named member of another struct
+func _(l Left, r Right) {
+	if l.x != nil {
+		fmt.Println(*l.x, *r.x) // want "Unsafely used 'x'"
+	}
+}
+
+type Request struct {
+	Headers map[string]string
+	Peers   []string
+	onClose func()
+	body    interface{}
+}
+
+func _(req *Request) {
+	req.Headers["x"] = "v" // want "Unsafely used 'req'" "Unsafely used 'Headers'"
+}
+
+func _(req Request) {
+	req.onClose() // want "Unsafely used 'onClose'"
+}
+
+func _(req Request) string {
+	return req.Peers[0] // want "Unsafely used 'Peers'"
+}
+
+func _(req Request) []string {
+	if len(req.Peers) > 0 {
+		return req.Peers[1:2] // want "Possible index out of range 'Peers'"
+	}
+	return nil
+}
+
+func _(req Request) string {
+	return req.body.(string) // want "Unsafely used 'body'"
+}
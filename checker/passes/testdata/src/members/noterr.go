@@ -0,0 +1,80 @@
+package members
+
+import (
+	"fmt"
+
+	"validator"
+)
+
+func _(req validator.Request) {
+	if req.Cfg != nil {
+		fmt.Println(req.Cfg.DB)
+	}
+}
+
+func _(opts struct{ timeout *int }) {
+	if opts.timeout != nil {
+		fmt.Println(*opts.timeout)
+	}
+}
+
+func _(n Node[string]) {
+	if n.next == nil {
+		return
+	}
+	fmt.Println(n.next.val)
+}
+
+func _(l Left, r Right) {
+	if l.x != nil && r.x != nil {
+		fmt.Println(*l.x, *r.x)
+	}
+}
+
+func _(req Request) {
+	if req.Headers != nil {
+		req.Headers["x"] = "v"
+	}
+	fmt.Println(req.Headers["y"], len(req.Headers)) // reading a nil map is safe
+	for k, v := range req.Headers {
+		fmt.Println(k, v)
+	}
+}
+
+func _(req Request) {
+	if req.onClose != nil {
+		req.onClose()
+	}
+}
+
+func _(req Request) string {
+	if len(req.Peers) > 0 {
+		return req.Peers[0]
+	}
+	return ""
+}
+
+func _(req Request) {
+	for i := range req.Peers {
+		fmt.Println(req.Peers[i])
+	}
+	fmt.Println(req.Peers[:0], append(req.Peers, "p"))
+}
+
+func _(req Request) {
+	if req.Headers == nil {
+		req.Headers = make(map[string]string)
+	}
+	req.Headers["x"] = "v"
+}
+
+func _(req Request) string {
+	if s, ok := req.body.(string); ok {
+		return s + req.body.(string)
+	}
+	switch req.body.(type) {
+	case int:
+		return fmt.Sprint(req.body.(int))
+	}
+	return ""
+}
@@ -4,11 +4,11 @@ import "fmt"
 
 type fptr = func(int, int) int
 
-func _(ptr *int) { // want "Declared 'ptr'"
+func _(ptr *int) {
 	fmt.Println(*ptr) // want "Unsafely used 'ptr'"
 }
 
-func _(f fptr) { // want "Declared 'f'"
+func _(f fptr) {
 	f(1, 2) // want "Unsafely used 'f'"
 }
 
@@ -20,6 +20,40 @@ type B struct {
 	a A
 }
 
-func _(b B) { // want "Declared 'a'"
+func _(b B) {
 	fmt.Println(*b.a.a) // want "Unsafely used 'a'"
 }
+
+type T struct {
+	n int
+}
+
+func (t T) Value() int {
+	return t.n
+}
+
+func (t *T) Ptr() int {
+	if t == nil {
+		return 0
+	}
+	return t.n
+}
+
+func _(t *T) {
+	fmt.Println(t.Value()) // want "Unsafely used 't'"
+}
+
+func _(t *T) {
+	f := t.Value // want "Unsafely used 't'"
+	fmt.Println(f)
+}
+
+func _(arr *[4]int) {
+	fmt.Println(arr[0]) // want "Unsafely used 'arr'"
+}
+
+func _(arr *[4]int) {
+	for _, v := range arr { // want "Unsafely used 'arr'"
+		fmt.Println(v)
+	}
+}
@@ -19,3 +19,16 @@ func _(b B) {
 		fmt.Println(*b.a.a)
 	}
 }
+
+func _(t *T) {
+	fmt.Println(t.Ptr())
+	f := t.Ptr
+	fmt.Println(f)
+}
+
+func _(arr *[4]int) {
+	fmt.Println(len(arr))
+	for i := range arr {
+		fmt.Println(i)
+	}
+}
@@ -0,0 +1,59 @@
+package reassign
+
+import "fmt"
+
+type Config struct {
+	DB *DB
+}
+
+type DB struct {
+	Name string
+}
+
+var configs = map[string]*Config{}
+
+func lookup(k string) *Config {
+	return configs[k]
+}
+
+func _(cfg *Config, k string) {
+	if cfg == nil {
+		return
+	}
+	cfg = lookup(k)
+	fmt.Println(cfg.DB) // want "Unsafely used 'cfg'"
+}
+
+func _(cfg *Config, k string) {
+	if cfg == nil {
+		cfg = lookup(k)
+	}
+	fmt.Println(cfg.DB) // want "Unsafely used 'cfg'"
+}
+
+func _(p *int) {
+	if p != nil {
+		p = nil
+		fmt.Println(*p) // want "Unsafely used 'p'"
+	}
+}
+
+func _(opts []int, others []int) {
+	opts = append(opts, others...)
+	fmt.Println(opts[0]) // want "Unsafely used 'opts'"
+}
+
+func _(f func()) {
+	if f == nil {
+		fmt.Println()
+	}
+	f() // want "Unsafely used 'f'"
+}
+
+func _(cfg *Config, db *DB) {
+	if cfg == nil || cfg.DB == nil {
+		return
+	}
+	cfg.DB = db
+	fmt.Println(cfg.DB.Name) // want "Unsafely used 'DB'"
+}
@@ -0,0 +1,72 @@
+package reassign
+
+import "fmt"
+
+func defaultConfig() *Config {
+	return &Config{DB: &DB{}}
+}
+
+func newConfig(name string) *Config {
+	if name == "" {
+		return defaultConfig()
+	}
+	return &Config{DB: &DB{Name: name}}
+}
+
+func noop() {}
+
+func _(cfg *Config) {
+	if cfg == nil {
+		cfg = defaultConfig()
+	}
+	fmt.Println(cfg.DB)
+}
+
+func _(cfg *Config, name string) {
+	if cfg == nil {
+		cfg = newConfig(name)
+	}
+	fmt.Println(cfg.DB)
+}
+
+func _(cfg *Config) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	fmt.Println(cfg.DB)
+}
+
+func _(opts []int) {
+	opts = append(opts, 1)
+	fmt.Println(opts[0])
+}
+
+func _(f func()) {
+	if f == nil {
+		f = noop
+	}
+	f()
+}
+
+func _(cfg *Config) {
+	cfg = new(Config)
+	fmt.Println(cfg.DB)
+}
+
+func _(cfg *Config, other *Config) {
+	if other == nil {
+		return
+	}
+	cfg = other
+	fmt.Println(cfg.DB)
+}
+
+func _(cfg *Config) {
+	if cfg == nil {
+		return
+	}
+	if cfg.DB == nil {
+		cfg.DB = &DB{}
+	}
+	fmt.Println(cfg.DB.Name)
+}
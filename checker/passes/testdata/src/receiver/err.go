@@ -0,0 +1,27 @@
+package receiver
+
+import (
+	"fmt"
+	"sync"
+)
+
+type Server struct {
+	mu   sync.Mutex
+	name string
+}
+
+func (s *Server) Handle() {
+	s.mu.Lock()         // want "Unsafely used 's'"
+	defer s.mu.Unlock() // want "Unsafely used 's'"
+}
+
+func (s *Server) Name() string {
+	return s.name // want "Unsafely used 's'"
+}
+
+func (s *Server) Run(f func()) {
+	go func() {
+		fmt.Println(s.name) // want "Unsafely used 's'"
+		f()                 // want "Unsafely used 'f'"
+	}()
+}
@@ -0,0 +1,35 @@
+package receiver
+
+import "fmt"
+
+func (s *Server) SafeName() string {
+	if s == nil {
+		return ""
+	}
+	return s.name
+}
+
+func (s *Server) Restart() {
+	s.Handle() // calling a method does not dereference the receiver
+}
+
+func (s Server) Copy() string {
+	return s.name
+}
+
+func (*Server) Static() {}
+
+// Getter mimics the generated protobuf getters, which are nil-safe by design
+type Getter struct {
+	value *int
+}
+
+func (g *Getter) GetValue() *int {
+	return g.value
+}
+
+func _(s *Server) {
+	if s != nil {
+		fmt.Println(s.Name())
+	}
+}
@@ -0,0 +1,56 @@
+package results
+
+import (
+	"errors"
+	"fmt"
+)
+
+type User struct {
+	Name string
+}
+
+type Cache struct {
+	users map[string]*User
+}
+
+// Get returns nil when the user is not cached
+func (c *Cache) Get(k string) *User {
+	if c == nil {
+		return nil
+	}
+	return c.users[k]
+}
+
+func lookup(id int) (*User, error) {
+	if id < 0 {
+		return nil, errors.New("invalid id")
+	}
+	return &User{}, nil
+}
+
+func _(c *Cache, k string) {
+	v := c.Get(k)
+	fmt.Println(v.Name) // want "Unsafely used 'v'"
+}
+
+func _(id int) {
+	u, _ := lookup(id)
+	fmt.Println(u.Name) // want "Unsafely used 'u'"
+}
+
+func _(id int) {
+	u, err := lookup(id)
+	fmt.Println(u.Name) // want "Unsafely used 'u'"
+	if err != nil {
+		return
+	}
+}
+
+func _(id int) error {
+	u, err := lookup(id)
+	if err == nil {
+		return nil
+	}
+	fmt.Println(u.Name) // want "Unsafely used 'u'"
+	return err
+}
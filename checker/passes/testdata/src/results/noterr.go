@@ -0,0 +1,34 @@
+package results
+
+import "fmt"
+
+func _(c *Cache, k string) {
+	if v := c.Get(k); v != nil {
+		fmt.Println(v.Name)
+	}
+}
+
+func _(id int) error {
+	u, err := lookup(id)
+	if err != nil {
+		return err
+	}
+	fmt.Println(u.Name)
+	return nil
+}
+
+func _(id int) {
+	if u, err := lookup(id); err == nil {
+		fmt.Println(u.Name)
+	}
+}
+
+func newUser() (*User, error) {
+	return &User{}, nil
+}
+
+// The results proven non-nil are not checked
+func _() {
+	u, _ := newUser()
+	fmt.Println(u.Name)
+}
@@ -2,10 +2,30 @@ package bytes
 
 import "fmt"
 
-func _(b []byte) { // want "Declared 'b'"
+func _(b []byte) {
 	fmt.Println(b[0:1]) // want "Unsafely used 'b'"
 }
 
-func _(b []byte) { // want "Declared 'b'"
+func _(b []byte) {
 	fmt.Println(b[0:1]) // want "Unsafely used 'b'"
 }
+
+func _(b []byte) {
+	if b == nil {
+		fmt.Println(b[0]) // want "Unsafely used 'b'"
+	}
+}
+
+func _(b []byte) {
+	b[0] = 1 // want "Unsafely used 'b'"
+}
+
+func _(b []byte, n int) {
+	fmt.Println(b[n:]) // want "Unsafely used 'b'"
+}
+
+func _(b []byte) {
+	if b != nil {
+		fmt.Println(b[0]) // want "Possible index out of range 'b'"
+	}
+}
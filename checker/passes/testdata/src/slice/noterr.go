@@ -3,7 +3,24 @@ package bytes
 import "fmt"
 
 func _(b []byte) {
-	if b == nil {
+	if len(b) > 0 {
 		fmt.Println(b[0])
 	}
 }
+
+func _(b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	fmt.Println(b[0])
+}
+
+// Ranging over, appending to, copying and slicing within the zero length of a nil slice never panic
+func _(b []byte, dst []byte) {
+	for i, v := range b {
+		fmt.Println(i, v)
+	}
+	fmt.Println(len(b), cap(b), append(b, 1), b[:], b[0:0], b[:0])
+	copy(dst, b)
+	clear(b)
+}
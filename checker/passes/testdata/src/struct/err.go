@@ -7,7 +7,7 @@ type S struct {
 	b int
 }
 
-func _(s *S) { // want "Declared 's'"
+func _(s *S) {
 	fmt.Println(s.a) // want "Unsafely used 's'"
 }
 
@@ -26,10 +26,10 @@ type C struct {
 
 // Test report and compiled program's reports are different each other.
 // The compiled one is more informative. We don't put much of effort in the test report
-func _(c C) { // want "Declared 'b'"
+func _(c C) {
 	fmt.Println(c.b.a) // want "Unsafely used 'b'"
 }
 
-func _(c C) { // want "Declared 'b'" "Declared 'a'"
+func _(c C) {
 	fmt.Println(c.b.a.n) // want "Unsafely used 'a'" "Unsafely used 'b'"
 }
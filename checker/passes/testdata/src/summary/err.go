@@ -0,0 +1,62 @@
+package summary
+
+import (
+	"fmt"
+
+	"validator"
+)
+
+func _(a *int, b []byte) {
+	checkArgs(a, b)
+	fmt.Println(*a, b[0]) // want "Unsafely used 'a'" "Unsafely used 'b'"
+}
+
+func _(a *int, b []byte) {
+	if err := checkArgs(a, b); err != nil {
+		fmt.Println(*a, b[0]) // want "Unsafely used 'a'" "Unsafely used 'b'"
+	}
+}
+
+func _(req *validator.Request) {
+	validator.Validate(req)
+	fmt.Println(req.Cfg) // want "Unsafely used 'req'"
+}
+
+func _(a *int, b *int) error {
+	if err := checkArgs(a, nil); err != nil {
+		return err
+	}
+	fmt.Println(*a, *b) // want "Unsafely used 'b'"
+	return nil
+}
+
+type node struct {
+	n    int
+	next *node
+}
+
+// the parameter is replaced in `ensure`, which leaves the caller's argument nil
+func ensure(p *node) {
+	if p == nil {
+		p = &node{}
+	}
+	fmt.Println(p.n)
+}
+
+func ensureAlias(p *node) {
+	q := p
+	if q == nil {
+		q = &node{}
+	}
+	fmt.Println(q.n)
+}
+
+func _(p *node) {
+	ensure(p)
+	fmt.Println(p.n) // want "Unsafely used 'p'"
+}
+
+func _(p *node) {
+	ensureAlias(p)
+	fmt.Println(p.n) // want "Unsafely used 'p'"
+}
@@ -0,0 +1,86 @@
+package summary
+
+import (
+	"errors"
+	"fmt"
+
+	"validator"
+)
+
+var errNil = errors.New("nil")
+
+func checkArgs(a *int, b []byte) error {
+	if a == nil || len(b) == 0 {
+		return errNil
+	}
+	return nil
+}
+
+func _(a *int, b []byte) error {
+	if err := checkArgs(a, b); err != nil {
+		return err
+	}
+	fmt.Println(*a, b[0])
+	return nil
+}
+
+func _(a *int, b []byte) error {
+	err := checkArgs(a, b)
+	if err != nil {
+		return fmt.Errorf("invalid args: %w", err)
+	}
+	fmt.Println(*a, b[0])
+	return nil
+}
+
+func _(a *int, b []byte) {
+	if checkArgs(a, b) == nil {
+		fmt.Println(*a, b[0])
+	}
+}
+
+func _(req *validator.Request) error {
+	if err := validator.Validate(req); err != nil {
+		return err
+	}
+	fmt.Println(req.Cfg.DB)
+	return nil
+}
+
+func _(req *validator.Request) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	fmt.Println(req.Cfg.DB)
+	return nil
+}
+
+func _(cfg *validator.Config) {
+	validator.MustConfig(cfg)
+	fmt.Println(cfg.DB.Name)
+}
+
+func mustPositive(p *int) {
+	if p == nil {
+		panic("nil p")
+	}
+}
+
+func _(p *int) {
+	mustPositive(p)
+	fmt.Println(*p)
+}
+
+func ensureNext(p *node) {
+	if p == nil {
+		panic("nil p")
+	}
+	if p.next == nil {
+		p.next = &node{}
+	}
+}
+
+func _(p *node) {
+	ensureNext(p)
+	fmt.Println(p.next.n)
+}
@@ -0,0 +1,54 @@
+package typednil
+
+import (
+	"fmt"
+	"io"
+)
+
+type MyErr struct {
+	msg string
+}
+
+func (e *MyErr) Error() string {
+	if e == nil {
+		return "<nil>"
+	}
+	return e.msg
+}
+
+// The caller's `err != nil` holds even if e is nil
+func _(e *MyErr) error {
+	return e // want "Possible typed nil 'e'"
+}
+
+func _(bad bool) error {
+	var e *MyErr
+	if bad {
+		e = &MyErr{"bad"}
+	}
+	return e // want "Possible typed nil 'e'"
+}
+
+func use(r io.Reader) {
+	if r != nil {
+		fmt.Println(r)
+	}
+}
+
+type Src struct{}
+
+func (*Src) Read(p []byte) (int, error) {
+	return 0, io.EOF
+}
+
+func _(s *Src) {
+	use(s) // want "Possible typed nil 's'"
+}
+
+type Wrapper struct {
+	err *MyErr
+}
+
+func _(w Wrapper) error {
+	return w.err // want "Possible typed nil 'err'"
+}
@@ -0,0 +1,38 @@
+package typednil
+
+import "fmt"
+
+func _(e *MyErr) error {
+	if e == nil {
+		return nil
+	}
+	return e
+}
+
+func _(bad bool) error {
+	var e *MyErr
+	if bad {
+		e = &MyErr{"bad"}
+		return e
+	}
+	return nil
+}
+
+// A pointer declared nil is left to the nil facts for the other uses
+func _(bad bool) {
+	var e *MyErr
+	if bad {
+		e = &MyErr{"bad"}
+	}
+	fmt.Println(e) // formatting a typed nil is harmless
+}
+
+func _(s *Src) {
+	if s != nil {
+		use(s)
+	}
+}
+
+func _() error {
+	return &MyErr{"always"}
+}
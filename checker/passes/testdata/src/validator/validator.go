@@ -0,0 +1,39 @@
+package validator
+
+import "errors"
+
+type Config struct {
+	DB *DB
+}
+
+type DB struct {
+	Name string
+}
+
+type Request struct {
+	Cfg *Config
+}
+
+func Validate(req *Request) error {
+	if req == nil {
+		return errors.New("nil request")
+	}
+	if req.Cfg == nil {
+		return errors.New("nil config")
+	}
+	return nil
+}
+
+func (req *Request) Validate() error {
+	return Validate(req)
+}
+
+func MustConfig(cfg *Config) {
+	if cfg == nil || cfg.DB == nil {
+		panic("nil config")
+	}
+}
+
+func DBName(cfg *Config) string {
+	return cfg.DB.Name
+}
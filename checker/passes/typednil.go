@@ -0,0 +1,116 @@
+package passes
+
+import (
+	"go/ast"
+	"go/types"
+
+	"github.com/hyunsooda/paramguard/checker/passtyps"
+)
+
+// nilPointers returns the local pointers declared or assigned nil, e.g., `var e *MyErr`,
+// which may still be nil when converted to an interface. Their other uses are left to the nil facts.
+func nilPointers(ctx passtyps.Context, body *ast.BlockStmt) []types.Object {
+	info := ctx.Pass.TypesInfo
+	escaped := escapedVars(ctx, body)
+	seen := make(map[types.Object]bool)
+	var ptrs []types.Object
+	add := func(ident *ast.Ident) {
+		obj, ok := info.ObjectOf(ident).(*types.Var)
+		if !ok || seen[obj] || escaped[obj] || !isConcretePtr(obj.Type()) || findObj(ctx.Params, obj) {
+			return
+		}
+		if obj.Pos() < body.Pos() || obj.Pos() >= body.End() {
+			return // declared outside, e.g., a package-level variable or captured by a closure
+		}
+		seen[obj] = true
+		ptrs = append(ptrs, obj)
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch stmt := n.(type) {
+		case *ast.FuncLit:
+			return false // analyzed on its own
+		case *ast.ValueSpec:
+			for i, name := range stmt.Names {
+				if len(stmt.Values) == 0 || (len(stmt.Values) == len(stmt.Names) && isNilExpr(ctx, stmt.Values[i], nil)) {
+					add(name)
+				}
+			}
+		case *ast.AssignStmt:
+			if len(stmt.Lhs) != len(stmt.Rhs) {
+				break
+			}
+			for i, lhs := range stmt.Lhs {
+				if ident, ok := unparen(lhs).(*ast.Ident); ok && isNilExpr(ctx, stmt.Rhs[i], nil) {
+					add(ident)
+				}
+			}
+		}
+		return true
+	})
+	return ptrs
+}
+
+func isConcretePtr(typ types.Type) bool {
+	if _, ok := typ.(*types.TypeParam); ok {
+		return false
+	}
+	_, ok := typ.Underlying().(*types.Pointer)
+	return ok
+}
+
+// typedNils returns the conversions of possibly nil pointers to interfaces in `n`, which make non-nil interfaces
+// holding a nil pointer: returning them as interface results (e.g., `return e` of `e *MyErr` as an `error`),
+// and passing them as non-empty interface arguments. The empty interface arguments are left out
+// since they are mostly formatted or encoded, where a typed nil is harmless.
+func (fg *flowGraph) typedNils(ctx passtyps.Context, n ast.Node) []*passtyps.ParamUsage {
+	var usages []*passtyps.ParamUsage
+	convert := func(expr ast.Expr, target types.Type, emptyIface bool) {
+		if target == nil {
+			return
+		}
+		if _, ok := target.(*types.TypeParam); ok {
+			return
+		}
+		iface, ok := target.Underlying().(*types.Interface)
+		if !ok || (iface.Empty() && !emptyIface) || !isConcretePtr(ctx.Pass.TypesInfo.TypeOf(expr)) {
+			return
+		}
+		if usage := paramValue(ctx, expr); usage != nil {
+			usage.UseAt = expr
+			usage.TypedNil = true
+			usage.Op = passtyps.OpTypedNil
+			usages = append(usages, usage)
+		}
+	}
+
+	switch node := n.(type) {
+	case *ast.ReturnStmt:
+		if fg.sig == nil || len(node.Results) != fg.sig.Results().Len() {
+			break
+		}
+		for i, result := range node.Results {
+			convert(result, fg.sig.Results().At(i).Type(), true)
+		}
+	case *ast.CallExpr:
+		if tv, ok := ctx.Pass.TypesInfo.Types[node.Fun]; !ok || tv.IsType() || tv.IsBuiltin() {
+			break
+		}
+		sig, ok := ctx.Pass.TypesInfo.TypeOf(node.Fun).Underlying().(*types.Signature)
+		if !ok {
+			break
+		}
+		params := sig.Params()
+		for i, arg := range node.Args {
+			var target types.Type
+			if sig.Variadic() && i >= params.Len()-1 {
+				if !node.Ellipsis.IsValid() {
+					target = params.At(params.Len() - 1).Type().(*types.Slice).Elem()
+				}
+			} else if i < params.Len() {
+				target = params.At(i).Type()
+			}
+			convert(arg, target, false)
+		}
+	}
+	return usages
+}
@@ -1,7 +1,9 @@
 package passtyps
 
 import (
+	"fmt"
 	"go/ast"
+	"go/types"
 	"io/ioutil"
 	"log"
 	"path/filepath"
@@ -19,34 +21,103 @@ type Config struct {
 		Pkg   string
 		Funcs []string
 	}
-	Log       bool
-	CallGraph bool
-	Maxpath   int
+	Log        bool
+	CallGraph  bool
+	Maxpath    int
+	Callers    string   // Findings on parameters every caller passes non-nil: kept as is (""), "drop" or "downgrade"
+	Receivers  bool     // Check pointer receivers as nilable parameters
+	NilSafe    []string // Types whose methods are nil-safe, e.g., "pkg.Type" or "github.com/org/pkg.*Getter"
+	Elements   bool     // Check the pointer, interface and function elements of slices and maps as nilable values
+	NilReturns []string // Functions returning nil when not found, e.g., "cache.Get" or "github.com/org/store.*.Find"
 }
 
 const (
 	FLAG_CONFIG_FILE_PATH = "config"
+	FLAG_RECEIVERS        = "receivers"
+	FLAG_ELEMENTS         = "elements"
+)
+
+const (
+	CALLERS_DROP      = "drop"      // Drop the findings on parameters every caller passes non-nil
+	CALLERS_DOWNGRADE = "downgrade" // Keep them, noting the callers pass non-nil
 )
 
 var Testing Test
 
 func ParseConfig(pass *analysis.Pass) *Config {
 	if Testing.On {
-		return nil
-	}
-	filePath := pass.Analyzer.Flags.Lookup(FLAG_CONFIG_FILE_PATH).Value.String()
-	data, err := ioutil.ReadFile(filePath)
-	if err != nil {
-		log.Fatalln(err)
+		return Testing.Config
 	}
 	var config Config
-	err = yaml.Unmarshal(data, &config)
-	if err != nil {
-		log.Fatalf("Error unmarshaling YAML: %v", err)
+	if filePath := pass.Analyzer.Flags.Lookup(FLAG_CONFIG_FILE_PATH).Value.String(); filePath != "" {
+		data, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		err = yaml.Unmarshal(data, &config)
+		if err != nil {
+			log.Fatalf("Error unmarshaling YAML: %v", err)
+		}
+		if err := config.Validate(); err != nil {
+			log.Fatalf("Invalid configuration %s: %v", filePath, err)
+		}
+	}
+	if receivers := pass.Analyzer.Flags.Lookup(FLAG_RECEIVERS); receivers != nil && receivers.Value.String() == "true" {
+		config.Receivers = true
+	}
+	if elements := pass.Analyzer.Flags.Lookup(FLAG_ELEMENTS); elements != nil && elements.Value.String() == "true" {
+		config.Elements = true
 	}
 	return &config
 }
 
+// Validate reports the values of the configuration out of their domains
+func (c *Config) Validate() error {
+	switch c.Callers {
+	case "", CALLERS_DROP, CALLERS_DOWNGRADE:
+	default:
+		return fmt.Errorf("unknown callers %q, want %q or %q", c.Callers, CALLERS_DROP, CALLERS_DOWNGRADE)
+	}
+	return nil
+}
+
+// IsNilSafe reports whether the methods of the named type are declared nil-safe in the configuration
+func IsNilSafe(config *Config, pkgPath, pkgName, typName string) bool {
+	if config == nil {
+		return false
+	}
+	for _, nilSafe := range config.NilSafe {
+		if strCmp(nilSafe, pkgName+"."+typName) || strCmp(nilSafe, pkgPath+"."+typName) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReturnsNil reports whether the function is declared to return nil when not found in the configuration.
+// Functions are named "pkg.Func", and methods "pkg.Type.Method".
+func ReturnsNil(config *Config, fn *types.Func) bool {
+	if config == nil || fn.Pkg() == nil {
+		return false
+	}
+	name := fn.Name()
+	if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+		typ := recv.Type()
+		if ptr, ok := typ.(*types.Pointer); ok {
+			typ = ptr.Elem()
+		}
+		if named, ok := typ.(*types.Named); ok {
+			name = named.Obj().Name() + "." + name
+		}
+	}
+	for _, nilReturn := range config.NilReturns {
+		if strCmp(nilReturn, fn.Pkg().Name()+"."+name) || strCmp(nilReturn, fn.Pkg().Path()+"."+name) {
+			return true
+		}
+	}
+	return false
+}
+
 func IsInExcludes(pass *analysis.Pass, fnDecl *ast.FuncDecl, config *Config) bool {
 	if Testing.On {
 		return false
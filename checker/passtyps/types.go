@@ -1,61 +1,126 @@
 package passtyps
 
 import (
+	"fmt"
 	"go/ast"
 	"go/token"
 	"go/types"
-	"sync"
+	"strings"
 
 	"golang.org/x/tools/go/analysis"
 )
 
 type ParamUsage struct {
-	Fn         *types.Func
-	Param      types.Object
-	Context    types.Object
-	GuardAt    ast.Node
-	UseAt      ast.Node
-	DeclaredAt token.Pos
+	Fn          *types.Func
+	Param       types.Object
+	Context     types.Object
+	Path        []types.Object // members accessed from `Context`, ending with `Param`
+	GuardAt     ast.Node
+	UseAt       ast.Node
+	DeclaredAt  token.Pos
+	IsNil       bool         // the guard proves the value is nil instead, e.g., the true branch of `p == nil`
+	MinLen      int          // minimum length proven by a len() guard, 0 if the guard does not check the length
+	LenAbove    types.Object // variable bounding the length from below, e.g., `i` in `i < len(b)`
+	LenMargin   int          // the length is at least `LenAbove` plus the margin, 1 for `i < len(b)`, 0 for `k <= len(b)`
+	OutOfRange  bool         // the use indexes beyond the length proven by its guard
+	AssertedTyp types.Type   // the dynamic type proven by a type guard, or asserted by a use, e.g., T in `p.(T)`
+	TypedNil    bool         // the use converts the possibly nil pointer to a non-nil interface, e.g., `return p` as an error
+	Op          OpKind       // the operation of the use
+	NearbyGuard ast.Node     // the closest check of the value preceding the use, which does not cover it
+	Reassigned  bool         // the guard holds on a new value of the parameter variable, not on the caller's argument
+
+	NonNilCallers int // number of call sites, all passing non-nil, when the finding is downgraded
 }
 
-type CallGraph = map[string][]string
+// OpKind classifies the operation of a use panicking (or blocking forever) on nil
+type OpKind string
+
+const (
+	OpDeref      OpKind = "deref"       // *p, or a value-receiver method called through p
+	OpMember     OpKind = "member"      // p.f, or a member of a member, e.g., p.cfg.db
+	OpMapWrite   OpKind = "map-write"   // m[k] = v
+	OpSliceIndex OpKind = "slice-index" // s[i], s[i:j]
+	OpFuncCall   OpKind = "func-call"   // f()
+	OpChan       OpKind = "chan"        // close(ch), ch <- v, <-ch, for range ch
+	OpInterface  OpKind = "interface"   // i.M(), i.(T)
+	OpTypedNil   OpKind = "typed-nil"   // a nil pointer converted to a non-nil interface
+)
+
+// GuardFact summarizes the parameters a function guarantees to be non-nil once it returns
+type GuardFact struct {
+	Guards        []GuardedParam   // hold on every return
+	NilErrGuards  []GuardedParam   // hold on the returns whose error result is nil
+	Unguarded     []UnguardedParam // parameters used without a guard
+	NonNilResults []int            // indexes of the results that are non-nil on every return
+}
+
+type GuardedParam struct {
+	Index   int      // index of the parameter, -1 for the receiver
+	Members []string // member path from the parameter, e.g., [Cfg DB] for `p.Cfg.DB`
+	MinLen  int      // minimum length proven for a slice, 0 if the guard does not check the length
+}
+
+type UnguardedParam struct {
+	Index int
+	Name  string
+	UseAt token.Position // position of the first unguarded use
+	Op    OpKind
+}
+
+type GuardSummaries = map[*types.Func]*GuardFact
+
+// ConfirmedCall is a call passing nil to a parameter the callee uses without a guard
+type ConfirmedCall struct {
+	Callee *types.Func
+	Param  string
+	CallAt *ast.CallExpr
+	Arg    ast.Expr
+	Use    *ParamUsage    // the unguarded use if the callee is declared in the analyzed package
+	UseLoc token.Position // the position of the unguarded use otherwise
+	Op     OpKind         // the operation of the unguarded use
+}
 
 type (
-	NamedTypes map[types.Type]map[*ast.Ident]types.Object
-	FuncParams = map[types.Object]ParamWithTypCollection
-	StructTyps = map[types.Object]*ast.StructType
+	Members    = map[*types.Var]bool // struct fields reachable from the parameters
+	FuncParams = map[types.Object]ParamWithMembers
 )
 
-type ParamWithTypCollection struct {
-	Params        []types.Object
-	TypCollection NamedTypes
+type ParamWithMembers struct {
+	Params  []types.Object
+	Members Members
 }
 
 type Context struct {
-	Pass          *analysis.Pass
-	Params        []types.Object
-	TypCollection NamedTypes
+	Pass     *analysis.Pass
+	Params   []types.Object
+	Members  Members
+	Aliases  map[types.Object]types.Object // locals always holding the value of a parameter, e.g., `q := p`
+	Elements map[ElementKey]*Element       // indexed elements of the parameters, nil unless checking the elements
 }
 
-type Test struct {
-	On           bool
-	lock         *sync.Mutex
-	ReportedMsgs map[string]bool
+// ElementKey identifies an element by its container, a parameter or a member of `Context`, and its index
+type ElementKey struct {
+	Container types.Object
+	Context   types.Object
+	Index     string
 }
 
-func (t Test) Lock() {
-	t.lock.Lock()
+// Element is an indexed element, e.g., `items[i]`, checked as a parameter of its own
+type Element struct {
+	Var   *types.Var
+	Index []types.Object // variables the index depends on
 }
 
-func (t Test) Unlock() {
-	t.lock.Unlock()
+type Test struct {
+	On     bool
+	Config *Config
 }
 
-func NewContext(pass *analysis.Pass, params []types.Object, typCollection NamedTypes) Context {
+func NewContext(pass *analysis.Pass, params []types.Object, members Members) Context {
 	return Context{
-		Pass:          pass,
-		Params:        params,
-		TypCollection: typCollection,
+		Pass:    pass,
+		Params:  params,
+		Members: members,
 	}
 }
 
@@ -68,15 +133,45 @@ func NewParamUsage(param types.Object, guardAt, useAt ast.Node, declaredAt token
 	}
 }
 
-func NewParamWithTypCollection(params []types.Object, typCollection NamedTypes) ParamWithTypCollection {
-	return ParamWithTypCollection{
-		Params:        params,
-		TypCollection: typCollection,
+// CallersNote explains why a downgraded finding is unlikely to happen
+func (u *ParamUsage) CallersNote() string {
+	if u.NonNilCallers == 0 {
+		return ""
+	}
+	return fmt.Sprintf("(non-nil at all %d call sites)", u.NonNilCallers)
+}
+
+func NewParamWithMembers(params []types.Object, members Members) ParamWithMembers {
+	return ParamWithMembers{
+		Params:  params,
+		Members: members,
+	}
+}
+
+func (*GuardFact) AFact() {}
+
+func (f *GuardFact) String() string {
+	unguarded := make([]string, len(f.Unguarded))
+	for i, param := range f.Unguarded {
+		unguarded[i] = fmt.Sprintf("#%d", param.Index)
+	}
+	nonNil := make([]string, len(f.NonNilResults))
+	for i, index := range f.NonNilResults {
+		nonNil[i] = fmt.Sprintf("#%d", index)
+	}
+	return fmt.Sprintf("guards(%s; err == nil: %s; unguarded: %s; non-nil results: %s)",
+		guardedParamsStr(f.Guards), guardedParamsStr(f.NilErrGuards), strings.Join(unguarded, ", "), strings.Join(nonNil, ", "))
+}
+
+func guardedParamsStr(guarded []GuardedParam) string {
+	strs := make([]string, len(guarded))
+	for i, g := range guarded {
+		strs[i] = strings.Join(append([]string{fmt.Sprintf("#%d", g.Index)}, g.Members...), ".")
 	}
+	return strings.Join(strs, ", ")
 }
 
 func InitTest() {
 	Testing.On = true
-	Testing.lock = &sync.Mutex{}
-	Testing.ReportedMsgs = make(map[string]bool)
+	Testing.Config = nil
 }
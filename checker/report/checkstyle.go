@@ -0,0 +1,67 @@
+package report
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+type checkstyle struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// checkstyleSeverities maps the levels of the findings to the Checkstyle severities
+var checkstyleSeverities = map[string]string{
+	"error":   "error",
+	"warning": "warning",
+	"note":    "info",
+}
+
+// WriteCheckstyle writes the findings as Checkstyle XML: an error per use site, or per nil argument
+// of a confirmed call, grouped by file in the order of the findings
+func WriteCheckstyle(w io.Writer, findings []*Finding, root string) error {
+	report := checkstyle{Version: "4.3"}
+	files := make(map[string]int)
+	for _, f := range findings {
+		name, _ := relPath(root, f.Pos.Filename)
+		i, ok := files[name]
+		if !ok {
+			i = len(report.Files)
+			files[name] = i
+			report.Files = append(report.Files, checkstyleFile{Name: name})
+		}
+		_, rule := RuleOf(f.Op)
+		report.Files[i].Errors = append(report.Files[i].Errors, checkstyleError{
+			Line:     f.Pos.Line,
+			Column:   f.Pos.Column,
+			Severity: checkstyleSeverities[f.Level()],
+			Message:  f.Message(),
+			Source:   "paramguard." + rule.ID + "." + rule.Name,
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
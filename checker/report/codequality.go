@@ -0,0 +1,69 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// codeQualityIssue is an issue of the GitLab Code Quality report, a subset of the Code Climate format
+type codeQualityIssue struct {
+	Type        string              `json:"type"`
+	CheckName   string              `json:"check_name"`
+	Description string              `json:"description"`
+	Content     *codeQualityContent `json:"content,omitempty"`
+	Categories  []string            `json:"categories"`
+	Fingerprint string              `json:"fingerprint"`
+	Severity    string              `json:"severity"`
+	Location    codeQualityLocation `json:"location"`
+}
+
+type codeQualityContent struct {
+	Body string `json:"body"`
+}
+
+type codeQualityLocation struct {
+	Path  string           `json:"path"`
+	Lines codeQualityLines `json:"lines"`
+}
+
+type codeQualityLines struct {
+	Begin int `json:"begin"`
+	End   int `json:"end,omitempty"`
+}
+
+// codeQualitySeverities maps the levels of the findings to the Code Quality severities
+var codeQualitySeverities = map[string]string{
+	"error":   "critical",
+	"warning": "major",
+	"note":    "minor",
+}
+
+// WriteCodeQuality writes the findings as a GitLab Code Quality report, locating the files relative to `root`,
+// which is the root of the repository for GitLab to link them
+func WriteCodeQuality(w io.Writer, findings []*Finding, root string) error {
+	issues := make([]codeQualityIssue, len(findings))
+	fingerprints := Fingerprints(findings)
+	for i, f := range findings {
+		_, rule := RuleOf(f.Op)
+		path, _ := relPath(root, f.Pos.Filename)
+		issues[i] = codeQualityIssue{
+			Type:        "issue",
+			CheckName:   rule.ID + " " + rule.Name,
+			Description: f.Message(),
+			Categories:  []string{"Bug Risk"},
+			Fingerprint: fingerprints[i],
+			Severity:    codeQualitySeverities[f.Level()],
+			Location: codeQualityLocation{
+				Path:  path,
+				Lines: codeQualityLines{Begin: f.Pos.Line, End: f.End.Line},
+			},
+		}
+		if body := details(f, root); body != "" {
+			issues[i].Content = &codeQualityContent{Body: body}
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(issues)
+}
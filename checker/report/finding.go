@@ -0,0 +1,225 @@
+package report
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/hyunsooda/paramguard/checker/passtyps"
+	"golang.org/x/tools/go/analysis"
+)
+
+// Finding is an unguarded use of a nilable parameter (or of its member or element),
+// or a call site confirmed to pass nil to a parameter its callee uses without a guard
+type Finding struct {
+	Pkg        string          // path of the package reported in
+	Func       string          // full name of the function declaring the parameter, the callee of a confirmed call
+	Param      string          // the parameter, e.g., `req` of `req.Cfg.DB`
+	Member     []string        // member path from the parameter, e.g., [Cfg DB]
+	Op         passtyps.OpKind // the operation of the unguarded use
+	OutOfRange bool            // the index may be beyond the length proven by its guard
+	Confirmed  bool            // the finding is a call site passing nil, reported at the nil argument
+	Pos        token.Position  // where the finding is reported: the use, or the nil argument of a confirmed call
+	End        token.Position
+	Declared   token.Position // declaration of the parameter, zero if the callee is declared in another package
+	Use        token.Position // the unguarded use
+	Guard      token.Position // the closest check of the value before the use, which does not cover it, if any
+	Guards     []string       // guards making the use safe, e.g., `req.Cfg.DB != nil`
+	CallPaths  [][]string     // feasible call paths to the function, each from its direct caller outward, with `callgraph` configured
+	Note       string         // why the finding is unlikely to happen, e.g., every caller passes non-nil
+
+	name                    string // the used value, e.g., `DB` or `items[i]`
+	pos, end, declAt, useAt token.Pos
+}
+
+// NewFindings builds the findings of the unguarded uses
+func NewFindings(pass *analysis.Pass, uses []*passtyps.ParamUsage) []*Finding {
+	findings := make([]*Finding, 0, len(uses))
+	for _, use := range uses {
+		param, member := use.Param.Name(), memberPath(use)
+		if use.Context != nil {
+			param = use.Context.Name()
+		}
+		f := &Finding{
+			Pkg:        pass.Pkg.Path(),
+			Func:       use.Fn.FullName(),
+			Param:      param,
+			Member:     member,
+			Op:         use.Op,
+			OutOfRange: use.OutOfRange,
+			Declared:   pass.Fset.Position(use.DeclaredAt),
+			Use:        pass.Fset.Position(use.UseAt.Pos()),
+			Guards:     guardCandidates(use),
+			Note:       use.CallersNote(),
+			name:       use.Param.Name(),
+			pos:        use.UseAt.Pos(),
+			end:        use.UseAt.End(),
+			declAt:     use.DeclaredAt,
+		}
+		f.Pos, f.End = f.Use, pass.Fset.Position(f.end)
+		if use.NearbyGuard != nil {
+			f.Guard = pass.Fset.Position(use.NearbyGuard.Pos())
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// NewConfirmedFindings builds the findings of the calls passing nil to a parameter that the callee uses without a guard
+func NewConfirmedFindings(pass *analysis.Pass, confirmed []*passtyps.ConfirmedCall) []*Finding {
+	findings := make([]*Finding, 0, len(confirmed))
+	for _, call := range confirmed {
+		f := &Finding{
+			Pkg:       pass.Pkg.Path(),
+			Func:      call.Callee.FullName(),
+			Param:     call.Param,
+			Op:        call.Op,
+			Confirmed: true,
+			Pos:       pass.Fset.Position(call.Arg.Pos()),
+			End:       pass.Fset.Position(call.Arg.End()),
+			Use:       call.UseLoc,
+			name:      call.Param,
+			pos:       call.Arg.Pos(),
+			end:       call.Arg.End(),
+		}
+		if call.Use != nil {
+			f.Guards = guardCandidates(call.Use)
+			f.Declared = pass.Fset.Position(call.Use.DeclaredAt)
+			f.Use = pass.Fset.Position(call.Use.UseAt.Pos())
+			if call.Use.NearbyGuard != nil {
+				f.Guard = pass.Fset.Position(call.Use.NearbyGuard.Pos())
+			}
+			f.declAt, f.useAt = call.Use.DeclaredAt, call.Use.UseAt.Pos()
+		} else if call.Op == passtyps.OpSliceIndex {
+			f.Guards = []string{fmt.Sprintf("len(%s) > 0", call.Param)}
+		} else {
+			f.Guards = []string{call.Param + " != nil"}
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// Expr returns the used value as written from the parameter, e.g., `req.Cfg.DB`
+func (f *Finding) Expr() string {
+	return strings.Join(append([]string{f.Param}, f.Member...), ".")
+}
+
+// Message describes the finding in a line
+func (f *Finding) Message() string {
+	if f.Confirmed {
+		msg := fmt.Sprintf("Confirmed nil passed to '%s' of %s", f.name, f.Func)
+		if f.declAt == token.NoPos && f.Use.IsValid() {
+			msg += fmt.Sprintf(", unsafely used at %s", f.Use)
+		}
+		return msg
+	}
+	prefix := "Unsafely used"
+	if f.OutOfRange {
+		prefix = "Possible index out of range"
+	} else if f.Op == passtyps.OpTypedNil {
+		prefix = "Possible typed nil"
+	}
+	msg := fmt.Sprintf("%s '%s'", prefix, f.name)
+	if len(f.Member) > 0 {
+		msg += fmt.Sprintf(" (member: '%s')", f.Expr())
+	}
+	if f.Note != "" {
+		msg += " " + f.Note
+	}
+	if len(f.CallPaths) > 0 {
+		paths := make([]string, len(f.CallPaths))
+		for i, path := range f.CallPaths {
+			paths[i] = callPathString(path)
+		}
+		msg += fmt.Sprintf("; feasible callgraph paths: %s", strings.Join(paths, ", "))
+	}
+	return msg
+}
+
+// callPathString writes the call path from its outermost caller, e.g., `pkg.main -> pkg.handle`
+func callPathString(path []string) string {
+	calls := make([]string, len(path))
+	for i, caller := range path {
+		calls[len(path)-1-i] = caller
+	}
+	return strings.Join(calls, " -> ")
+}
+
+// Diagnostic positions the finding at its use, or at the nil argument of a confirmed call,
+// with the declaration of the parameter (or the use in the callee) as related information
+func (f *Finding) Diagnostic() analysis.Diagnostic {
+	diag := analysis.Diagnostic{
+		Pos:      f.pos,
+		End:      f.end,
+		Category: string(f.Op),
+		Message:  f.Message(),
+	}
+	if f.declAt == token.NoPos {
+		return diag
+	}
+	if f.Confirmed {
+		diag.Related = append(diag.Related, analysis.RelatedInformation{Pos: f.declAt, Message: fmt.Sprintf("Declared '%s' of %s", f.name, f.Func)})
+		diag.Related = append(diag.Related, analysis.RelatedInformation{Pos: f.useAt, Message: "unsafely used here"})
+		return diag
+	}
+	diag.Related = []analysis.RelatedInformation{{Pos: f.declAt, Message: fmt.Sprintf("Declared '%s' of %s", f.Param, f.Func)}}
+	return diag
+}
+
+// Report reports the findings through the pass, each once, and returns them without the duplicates
+func Report(pass *analysis.Pass, findings []*Finding) []*Finding {
+	reported := make(map[token.Pos]map[string]bool)
+	unique := findings[:0]
+	for _, f := range findings {
+		diag := f.Diagnostic()
+		if reported[diag.Pos] == nil {
+			reported[diag.Pos] = make(map[string]bool)
+		}
+		if reported[diag.Pos][diag.Message] {
+			continue
+		}
+		reported[diag.Pos][diag.Message] = true
+		pass.Report(diag)
+		unique = append(unique, f)
+	}
+	return unique
+}
+
+func memberPath(use *passtyps.ParamUsage) []string {
+	if use.Context == nil {
+		return nil
+	}
+	if len(use.Path) == 0 {
+		return []string{use.Param.Name()}
+	}
+	path := make([]string, len(use.Path))
+	for i, member := range use.Path {
+		path[i] = member.Name()
+	}
+	return path
+}
+
+// guardCandidates returns the guards that make the use safe, e.g., `len(b) > 2` for `b[2]`
+func guardCandidates(use *passtyps.ParamUsage) []string {
+	expr := use.Param.Name()
+	if use.Context != nil {
+		expr = strings.Join(append([]string{use.Context.Name()}, memberPath(use)...), ".")
+	}
+	if use.Op != passtyps.OpSliceIndex {
+		return []string{expr + " != nil"}
+	}
+	switch at := use.UseAt.(type) {
+	case *ast.IndexExpr:
+		return []string{fmt.Sprintf("len(%s) > %s", expr, types.ExprString(at.Index))}
+	case *ast.SliceExpr:
+		for _, bound := range []ast.Expr{at.Max, at.High, at.Low} {
+			if bound != nil {
+				return []string{fmt.Sprintf("len(%s) >= %s", expr, types.ExprString(bound))}
+			}
+		}
+	}
+	return []string{fmt.Sprintf("len(%s) > 0", expr)}
+}
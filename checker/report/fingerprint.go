@@ -0,0 +1,23 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+)
+
+// Fingerprints identify the findings across runs regardless of their lines: a finding is keyed by its rule,
+// file, function and used value, and the findings sharing a key are told apart by their order
+func Fingerprints(findings []*Finding) []string {
+	fingerprints := make([]string, len(findings))
+	occurrences := make(map[string]int)
+	for i, f := range findings {
+		_, rule := RuleOf(f.Op)
+		key := fmt.Sprintf("%s|%t|%s|%s|%s|%s", rule.ID, f.Confirmed, filepath.Base(f.Pos.Filename), f.Func, f.Expr(), f.name)
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d", key, occurrences[key])))
+		occurrences[key]++
+		fingerprints[i] = hex.EncodeToString(sum[:])
+	}
+	return fingerprints
+}
@@ -0,0 +1,392 @@
+package report
+
+import (
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// snippetContext is the number of lines shown around a marked line of a snippet
+const snippetContext = 2
+
+type htmlReport struct {
+	Total int
+	Rules []htmlRule
+	Pkgs  []*htmlPkg
+}
+
+type htmlRule struct {
+	Rule
+	Count int
+}
+
+type htmlPkg struct {
+	ID    string
+	Name  string
+	Count int
+	Funcs []*htmlFunc
+}
+
+type htmlFunc struct {
+	ID       string
+	Name     string
+	Findings []htmlFinding
+}
+
+type htmlFinding struct {
+	ID       string
+	Rule     Rule
+	Level    string
+	Message  string
+	Pkg      string
+	Location string
+	Guards   []string
+	Snippets []htmlSnippet
+	CallTree *htmlCallNode
+	NPaths   int
+}
+
+type htmlSnippet struct {
+	File  string
+	Lines []htmlLine
+}
+
+type htmlLine struct {
+	No    int
+	Class string // decl, guard or use if the line is marked
+	Label string
+	Code  template.HTML
+}
+
+// htmlCallNode is a function of the feasible call paths, called by its callers
+type htmlCallNode struct {
+	Name    string
+	Callers []*htmlCallNode
+}
+
+// mark is a line of a snippet to highlight
+type mark struct {
+	line         int
+	class, label string
+}
+
+// WriteHTML writes the findings as a self-contained HTML report, with the source snippets read from the files
+// and the paths shown relative to `root`
+func WriteHTML(w io.Writer, findings []*Finding, root string) error {
+	report := htmlReport{Total: len(findings)}
+	counts := make(map[string]int)
+	pkgs := make(map[string]*htmlPkg)
+	funcs := make(map[[2]string]*htmlFunc)
+	sources := make(map[string][]template.HTML)
+	for i, f := range findings {
+		_, rule := RuleOf(f.Op)
+		counts[rule.ID]++
+		pkg, ok := pkgs[f.Pkg]
+		if !ok {
+			pkg = &htmlPkg{Name: f.Pkg}
+			pkgs[f.Pkg] = pkg
+			report.Pkgs = append(report.Pkgs, pkg)
+		}
+		pkg.Count++
+		fn, ok := funcs[[2]string{f.Pkg, f.Func}]
+		if !ok {
+			fn = &htmlFunc{Name: f.Func}
+			funcs[[2]string{f.Pkg, f.Func}] = fn
+			pkg.Funcs = append(pkg.Funcs, fn)
+		}
+		file, _ := relPath(root, f.Pos.Filename)
+		fn.Findings = append(fn.Findings, htmlFinding{
+			ID:       fmt.Sprintf("f%d", i),
+			Rule:     rule,
+			Level:    f.Level(),
+			Message:  f.Message(),
+			Pkg:      f.Pkg,
+			Location: fmt.Sprintf("%s:%d:%d", file, f.Pos.Line, f.Pos.Column),
+			Guards:   f.Guards,
+			Snippets: snippets(f, root, sources),
+			CallTree: callTree(f),
+			NPaths:   len(f.CallPaths),
+		})
+	}
+	for _, rule := range Rules {
+		if counts[rule.ID] > 0 {
+			report.Rules = append(report.Rules, htmlRule{rule, counts[rule.ID]})
+		}
+	}
+	sort.Slice(report.Pkgs, func(i, j int) bool {
+		return report.Pkgs[i].Name < report.Pkgs[j].Name
+	})
+	for i, pkg := range report.Pkgs {
+		pkg.ID = fmt.Sprintf("p%d", i)
+		for j, fn := range pkg.Funcs {
+			fn.ID = fmt.Sprintf("p%d-%d", i, j)
+		}
+	}
+	return htmlTemplate.Execute(w, report)
+}
+
+// snippets returns the source around the declaration, the nearby guard and the use (or the nil argument
+// of a confirmed call), merging the marked lines close to each other into a snippet
+func snippets(f *Finding, root string, sources map[string][]template.HTML) []htmlSnippet {
+	marks := make(map[string][]mark)
+	add := func(pos token.Position, class, label string) {
+		if pos.IsValid() {
+			marks[pos.Filename] = append(marks[pos.Filename], mark{pos.Line, class, label})
+		}
+	}
+	add(f.Declared, "decl", "declared")
+	add(f.Guard, "guard", "nearby guard")
+	if f.Confirmed {
+		add(f.Pos, "use", "nil passed")
+		add(f.Use, "use", "unsafely used")
+	} else {
+		add(f.Pos, "use", "unsafely used")
+	}
+
+	files := make([]string, 0, len(marks))
+	for file := range marks {
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		// the file of the finding first
+		if (files[i] == f.Pos.Filename) != (files[j] == f.Pos.Filename) {
+			return files[i] == f.Pos.Filename
+		}
+		return files[i] < files[j]
+	})
+
+	var snippets []htmlSnippet
+	for _, file := range files {
+		lines, ok := sources[file]
+		if !ok {
+			src, err := os.ReadFile(file)
+			if err == nil {
+				lines = highlight(src)
+			}
+			sources[file] = lines
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		fileMarks := marks[file]
+		sort.SliceStable(fileMarks, func(i, j int) bool {
+			return fileMarks[i].line < fileMarks[j].line
+		})
+		name, _ := relPath(root, file)
+		var snippet *htmlSnippet
+		last := 0
+		for _, m := range fileMarks {
+			from, to := m.line-snippetContext, m.line+snippetContext
+			if from < 1 {
+				from = 1
+			}
+			if to > len(lines) {
+				to = len(lines)
+			}
+			if snippet == nil || from > last+1 {
+				snippets = append(snippets, htmlSnippet{File: name})
+				snippet = &snippets[len(snippets)-1]
+				last = from - 1
+			}
+			for no := last + 1; no <= to; no++ {
+				snippet.Lines = append(snippet.Lines, htmlLine{No: no, Code: lines[no-1]})
+			}
+			if to > last {
+				last = to
+			}
+			for i := range snippet.Lines {
+				if line := &snippet.Lines[i]; line.No == m.line && line.Class == "" {
+					line.Class, line.Label = m.class, m.label
+				}
+			}
+		}
+	}
+	return snippets
+}
+
+// callTree merges the feasible call paths into the tree of the callers of the function, sharing their common callers
+func callTree(f *Finding) *htmlCallNode {
+	if len(f.CallPaths) == 0 {
+		return nil
+	}
+	root := &htmlCallNode{Name: f.Func}
+	for _, path := range f.CallPaths {
+		node := root
+		for _, caller := range path {
+			var next *htmlCallNode
+			for _, callerNode := range node.Callers {
+				if callerNode.Name == caller {
+					next = callerNode
+					break
+				}
+			}
+			if next == nil {
+				next = &htmlCallNode{Name: caller}
+				node.Callers = append(node.Callers, next)
+			}
+			node = next
+		}
+	}
+	return root
+}
+
+// highlight returns the HTML lines of the Go source, with its keywords, literals and comments highlighted
+func highlight(src []byte) []template.HTML {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	s.Init(file, src, nil, scanner.ScanComments)
+
+	var lines []template.HTML
+	var line strings.Builder
+	write := func(text, class string) {
+		for i, part := range strings.Split(text, "\n") {
+			if i > 0 {
+				lines = append(lines, template.HTML(line.String()))
+				line.Reset()
+			}
+			if part == "" {
+				continue
+			}
+			if class == "" {
+				line.WriteString(template.HTMLEscapeString(part))
+			} else {
+				fmt.Fprintf(&line, `<span class="%s">%s</span>`, class, template.HTMLEscapeString(part))
+			}
+		}
+	}
+	offset := 0
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		class := tokenClass(tok, lit)
+		if class == "" {
+			continue
+		}
+		start := file.Offset(pos)
+		if start < offset || start+len(lit) > len(src) || string(src[start:start+len(lit)]) != lit {
+			continue // e.g., a raw string whose carriage returns are dropped from its literal
+		}
+		write(string(src[offset:start]), "")
+		write(lit, class)
+		offset = start + len(lit)
+	}
+	write(string(src[offset:]), "")
+	return append(lines, template.HTML(line.String()))
+}
+
+func tokenClass(tok token.Token, lit string) string {
+	switch {
+	case tok.IsKeyword(), tok == token.IDENT && (lit == "nil" || lit == "true" || lit == "false"):
+		return "kw"
+	case tok == token.STRING || tok == token.CHAR:
+		return "str"
+	case tok == token.INT || tok == token.FLOAT || tok == token.IMAG:
+		return "num"
+	case tok == token.COMMENT:
+		return "com"
+	}
+	return ""
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>ParameterGuard report</title>
+<style>
+body { margin: 0; font: 14px/1.4 -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #1f2328; display: flex; }
+nav { width: 320px; height: 100vh; overflow: auto; position: sticky; top: 0; border-right: 1px solid #d0d7de; padding: 12px; box-sizing: border-box; background: #f6f8fa; flex-shrink: 0; }
+nav ul { list-style: none; padding-left: 12px; margin: 4px 0; }
+nav a { color: #0969da; text-decoration: none; word-break: break-all; }
+main { flex: 1; padding: 16px 24px; min-width: 0; }
+h1 { font-size: 20px; margin: 0 0 12px; }
+h2 { font-size: 17px; border-bottom: 1px solid #d0d7de; padding-bottom: 4px; }
+h3 { font-size: 15px; font-family: monospace; }
+fieldset { border: 1px solid #d0d7de; margin-bottom: 16px; }
+label { margin-right: 12px; white-space: nowrap; }
+.finding { border: 1px solid #d0d7de; border-radius: 6px; padding: 8px 12px; margin: 8px 0 16px; }
+.badge { display: inline-block; padding: 0 6px; border-radius: 10px; font-size: 12px; margin-right: 6px; background: #ddf4ff; }
+.error { background: #ffebe9; } .warning { background: #fff8c5; } .note { background: #eaeef2; }
+.file { font-family: monospace; color: #57606a; margin-top: 8px; }
+pre { margin: 2px 0; background: #f6f8fa; border-radius: 4px; overflow: auto; }
+pre div { padding: 0 8px; white-space: pre; }
+pre div.decl { background: #ddf4ff; } pre div.guard { background: #dafbe1; } pre div.use { background: #ffebe9; }
+.no { display: inline-block; width: 40px; color: #8c959f; user-select: none; }
+.label { float: right; font-size: 12px; color: #57606a; }
+.kw { color: #cf222e; } .str { color: #0a3069; } .num { color: #0550ae; } .com { color: #6e7781; }
+.tree { margin-left: 16px; font-family: monospace; } details.tree summary { cursor: pointer; }
+.hidden { display: none; }
+</style>
+</head>
+<body>
+<nav>
+<strong>{{.Total}} findings</strong>
+<ul>
+{{range .Pkgs}}<li class="nav-pkg" data-target="{{.ID}}"><a href="#{{.ID}}">{{.Name}}</a> ({{.Count}})
+<ul>{{range .Funcs}}<li class="nav-func" data-target="{{.ID}}"><a href="#{{.ID}}">{{.Name}}</a> ({{len .Findings}})</li>{{end}}</ul>
+</li>
+{{end}}</ul>
+</nav>
+<main>
+<h1>ParameterGuard report</h1>
+<fieldset>
+<legend>Filter</legend>
+<div>{{range .Rules}}<label><input type="checkbox" class="rule" value="{{.ID}}" checked> {{.ID}} {{.Name}} ({{.Count}})</label>{{end}}</div>
+<div><label>Package <select id="pkg"><option value="">all</option>{{range .Pkgs}}<option>{{.Name}}</option>{{end}}</select></label>
+<label>Search <input type="search" id="search"></label></div>
+</fieldset>
+{{range .Pkgs}}<section class="pkg" id="{{.ID}}" data-pkg="{{.Name}}">
+<h2>{{.Name}}</h2>
+{{range .Funcs}}<div class="func" id="{{.ID}}">
+<h3>{{.Name}}</h3>
+{{range .Findings}}<div class="finding" id="{{.ID}}" data-rule="{{.Rule.ID}}">
+<div><span class="badge">{{.Rule.ID}} {{.Rule.Name}}</span><span class="badge {{.Level}}">{{.Level}}</span>
+<strong>{{.Message}}</strong> <a href="#{{.ID}}">{{.Location}}</a></div>
+{{if .Guards}}<div>Guard with: {{range $i, $g := .Guards}}{{if $i}} or {{end}}<code>{{$g}}</code>{{end}}</div>{{end}}
+{{range .Snippets}}<div class="file">{{.File}}</div>
+<pre>{{range .Lines}}<div class="{{.Class}}"><span class="no">{{.No}}</span>{{.Code}}{{if .Label}}<span class="label">{{.Label}}</span>{{end}}</div>{{end}}</pre>
+{{end}}{{if .CallTree}}<details><summary>Feasible callgraph paths ({{.NPaths}})</summary>{{template "call" .CallTree}}</details>{{end}}
+</div>
+{{end}}</div>
+{{end}}</section>
+{{end}}
+</main>
+<script>
+function filter() {
+  var rules = {};
+  document.querySelectorAll("input.rule").forEach(function (box) { rules[box.value] = box.checked; });
+  var pkg = document.getElementById("pkg").value;
+  var search = document.getElementById("search").value.toLowerCase();
+  document.querySelectorAll("section.pkg").forEach(function (section) {
+    var pkgShown = false;
+    section.querySelectorAll("div.func").forEach(function (fn) {
+      var fnShown = false;
+      fn.querySelectorAll("div.finding").forEach(function (finding) {
+        var shown = rules[finding.dataset.rule] && (pkg === "" || section.dataset.pkg === pkg) &&
+          (search === "" || finding.textContent.toLowerCase().indexOf(search) >= 0);
+        finding.classList.toggle("hidden", !shown);
+        fnShown = fnShown || shown;
+      });
+      fn.classList.toggle("hidden", !fnShown);
+      document.querySelector('li.nav-func[data-target="' + fn.id + '"]').classList.toggle("hidden", !fnShown);
+      pkgShown = pkgShown || fnShown;
+    });
+    section.classList.toggle("hidden", !pkgShown);
+    document.querySelector('li.nav-pkg[data-target="' + section.id + '"]').classList.toggle("hidden", !pkgShown);
+  });
+}
+document.querySelectorAll("input.rule, #pkg").forEach(function (input) { input.addEventListener("change", filter); });
+document.getElementById("search").addEventListener("input", filter);
+</script>
+</body>
+</html>
+{{define "call"}}{{if .Callers}}<details class="tree" open><summary>{{.Name}}</summary>{{range .Callers}}{{template "call" .}}{{end}}</details>{{else}}<div class="tree">{{.Name}}</div>{{end}}{{end}}
+`))
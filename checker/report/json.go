@@ -0,0 +1,115 @@
+package report
+
+import (
+	"encoding/json"
+	"go/token"
+	"io"
+)
+
+// JSONVersion is the version of the JSON report format, incremented on incompatible changes only
+const JSONVersion = 1
+
+// JSONReport is the JSON report: the findings of a run
+type JSONReport struct {
+	Version  int           `json:"version"`
+	Tool     string        `json:"tool"`
+	Findings []JSONFinding `json:"findings"`
+}
+
+// JSONFinding is a finding in the JSON report, also written on its own line in the JSON Lines report
+type JSONFinding struct {
+	Version     int           `json:"version,omitempty"` // set in the JSON Lines report only
+	Rule        string        `json:"rule"`
+	Op          string        `json:"op"`
+	Level       string        `json:"level"`
+	Message     string        `json:"message"`
+	Pkg         string        `json:"pkg"`
+	Func        string        `json:"func"`
+	Param       string        `json:"param"`
+	Member      []string      `json:"member,omitempty"`
+	Expr        string        `json:"expr"`
+	Confirmed   bool          `json:"confirmed,omitempty"`
+	OutOfRange  bool          `json:"outOfRange,omitempty"`
+	Pos         JSONPosition  `json:"pos"`
+	End         JSONPosition  `json:"end"`
+	Declared    *JSONPosition `json:"declared,omitempty"`
+	Use         *JSONPosition `json:"use,omitempty"`
+	Guard       *JSONPosition `json:"guard,omitempty"`
+	Guards      []string      `json:"guards,omitempty"`
+	CallPaths   [][]string    `json:"callPaths,omitempty"` // each from the direct caller outward
+	Note        string        `json:"note,omitempty"`
+	Fingerprint string        `json:"fingerprint"`
+}
+
+type JSONPosition struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// NewJSONReport builds the JSON report of the findings
+func NewJSONReport(findings []*Finding) JSONReport {
+	report := JSONReport{
+		Version:  JSONVersion,
+		Tool:     "paramguard",
+		Findings: make([]JSONFinding, len(findings)),
+	}
+	fingerprints := Fingerprints(findings)
+	for i, f := range findings {
+		_, rule := RuleOf(f.Op)
+		report.Findings[i] = JSONFinding{
+			Rule:        rule.ID,
+			Op:          string(f.Op),
+			Level:       f.Level(),
+			Message:     f.Message(),
+			Pkg:         f.Pkg,
+			Func:        f.Func,
+			Param:       f.Param,
+			Member:      f.Member,
+			Expr:        f.Expr(),
+			Confirmed:   f.Confirmed,
+			OutOfRange:  f.OutOfRange,
+			Pos:         jsonPosition(f.Pos),
+			End:         jsonPosition(f.End),
+			Declared:    optionalPosition(f.Declared),
+			Use:         optionalPosition(f.Use),
+			Guard:       optionalPosition(f.Guard),
+			Guards:      f.Guards,
+			CallPaths:   f.CallPaths,
+			Note:        f.Note,
+			Fingerprint: fingerprints[i],
+		}
+	}
+	return report
+}
+
+// WriteJSON writes the findings as a JSON report
+func WriteJSON(w io.Writer, findings []*Finding) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(NewJSONReport(findings))
+}
+
+// WriteJSONLines writes the findings as JSON Lines, a finding per line tagged with the format version
+func WriteJSONLines(w io.Writer, findings []*Finding) error {
+	enc := json.NewEncoder(w)
+	for _, f := range NewJSONReport(findings).Findings {
+		f.Version = JSONVersion
+		if err := enc.Encode(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func jsonPosition(pos token.Position) JSONPosition {
+	return JSONPosition{File: pos.Filename, Line: pos.Line, Column: pos.Column}
+}
+
+func optionalPosition(pos token.Position) *JSONPosition {
+	if !pos.IsValid() {
+		return nil
+	}
+	p := jsonPosition(pos)
+	return &p
+}
@@ -0,0 +1,103 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string       `xml:"name,attr"`
+	ClassName string       `xml:"classname,attr"`
+	File      string       `xml:"file,attr,omitempty"`
+	Line      int          `xml:"line,attr,omitempty"`
+	Failure   junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the findings as JUnit XML: a failed test case per finding, grouped by package into test suites
+func WriteJUnit(w io.Writer, findings []*Finding, root string) error {
+	suites := junitSuites{Name: "paramguard", Tests: len(findings), Failures: len(findings)}
+	byPkg := make(map[string]*junitSuite)
+	var pkgs []string
+	for _, f := range findings {
+		suite, ok := byPkg[f.Pkg]
+		if !ok {
+			suite = &junitSuite{Name: f.Pkg}
+			byPkg[f.Pkg] = suite
+			pkgs = append(pkgs, f.Pkg)
+		}
+		_, rule := RuleOf(f.Op)
+		file, _ := relPath(root, f.Pos.Filename)
+		suite.Cases = append(suite.Cases, junitCase{
+			Name:      fmt.Sprintf("%s %s:%d:%d", rule.ID, file, f.Pos.Line, f.Pos.Column),
+			ClassName: f.Func,
+			File:      file,
+			Line:      f.Pos.Line,
+			Failure: junitFailure{
+				Message: f.Message(),
+				Type:    rule.ID + " " + rule.Name,
+				Text:    details(f, root),
+			},
+		})
+		suite.Tests++
+		suite.Failures++
+	}
+	sort.Strings(pkgs)
+	for _, pkg := range pkgs {
+		suites.Suites = append(suites.Suites, *byPkg[pkg])
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// details describes the finding over several lines, e.g., for the body of a test failure
+func details(f *Finding, root string) string {
+	var lines []string
+	if f.Declared.IsValid() {
+		file, _ := relPath(root, f.Declared.Filename)
+		lines = append(lines, fmt.Sprintf("Declared '%s' of %s at %s:%d:%d", f.Param, f.Func, file, f.Declared.Line, f.Declared.Column))
+	}
+	if f.Use.IsValid() {
+		file, _ := relPath(root, f.Use.Filename)
+		lines = append(lines, fmt.Sprintf("Unsafely used '%s' at %s:%d:%d", f.Expr(), file, f.Use.Line, f.Use.Column))
+	}
+	if len(f.Guards) > 0 {
+		lines = append(lines, "Guard with: "+strings.Join(f.Guards, " or "))
+	}
+	for _, path := range f.CallPaths {
+		lines = append(lines, "Feasible callgraph path: "+callPathString(path))
+	}
+	return strings.Join(lines, "\n")
+}
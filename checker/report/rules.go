@@ -0,0 +1,44 @@
+package report
+
+import "github.com/hyunsooda/paramguard/checker/passtyps"
+
+// Rule describes the findings of an operation kind. Its ID is stable across releases.
+type Rule struct {
+	ID          string
+	Name        string
+	Op          passtyps.OpKind
+	Description string
+}
+
+var Rules = []Rule{
+	{"PG001", "nil-pointer-dereference", passtyps.OpDeref, "A possibly nil pointer parameter is dereferenced without a guard."},
+	{"PG002", "nil-member-access", passtyps.OpMember, "A member of a parameter is accessed through a possibly nil pointer without a guard."},
+	{"PG003", "nil-map-write", passtyps.OpMapWrite, "A possibly nil map parameter is written without a guard."},
+	{"PG004", "slice-index-out-of-range", passtyps.OpSliceIndex, "A slice parameter is indexed or sliced beyond the length proven by its guard."},
+	{"PG005", "nil-func-call", passtyps.OpFuncCall, "A possibly nil function parameter is called without a guard."},
+	{"PG006", "nil-channel-operation", passtyps.OpChan, "A possibly nil channel parameter is closed, sent to, received from or ranged over without a guard."},
+	{"PG007", "nil-interface-method-call", passtyps.OpInterface, "A method is called on, or a type asserted from, a possibly nil interface parameter without a guard."},
+	{"PG008", "typed-nil-interface", passtyps.OpTypedNil, "A possibly nil pointer is converted to a non-nil interface."},
+}
+
+// RuleOf returns the rule of the operation kind, defaulting to the dereference
+func RuleOf(op passtyps.OpKind) (int, Rule) {
+	for i, rule := range Rules {
+		if rule.Op == op {
+			return i, rule
+		}
+	}
+	return 0, Rules[0]
+}
+
+// Level returns the severity of the finding: "error" for a confirmed nil argument,
+// "note" for a finding unlikely to happen, and "warning" otherwise
+func (f *Finding) Level() string {
+	if f.Confirmed {
+		return "error"
+	}
+	if f.Note != "" {
+		return "note"
+	}
+	return "warning"
+}
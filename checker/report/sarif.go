@@ -0,0 +1,212 @@
+package report
+
+import (
+	"encoding/json"
+	"go/token"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	srcRoot      = "%SRCROOT%"
+	toolURI      = "https://github.com/hyunsooda/paramguard"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifact `json:"originalUriBaseIds,omitempty"`
+	Results            []sarifResult            `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	RelatedLocations    []sarifLocation   `json:"relatedLocations,omitempty"`
+	CodeFlows           []sarifCodeFlow   `json:"codeFlows,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+}
+
+type sarifLocation struct {
+	ID               int                    `json:"id,omitempty"`
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+	Message          *sarifMessage          `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+	Region           sarifRegion   `json:"region"`
+}
+
+type sarifArtifact struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+type sarifCodeFlow struct {
+	ThreadFlows []sarifThreadFlow `json:"threadFlows"`
+}
+
+type sarifThreadFlow struct {
+	Locations []sarifThreadFlowLocation `json:"locations"`
+}
+
+type sarifThreadFlowLocation struct {
+	Location sarifLocation `json:"location"`
+}
+
+// WriteSARIF writes the findings as a SARIF 2.1.0 log, locating the files relative to `root`
+func WriteSARIF(w io.Writer, findings []*Finding, root string) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "paramguard",
+			InformationURI: toolURI,
+			Rules:          make([]sarifRule, len(Rules)),
+		}},
+		Results: make([]sarifResult, 0, len(findings)),
+	}
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+		run.OriginalURIBaseIDs = map[string]sarifArtifact{srcRoot: {URI: fileURI(root) + "/"}}
+	}
+	for i, rule := range Rules {
+		run.Tool.Driver.Rules[i] = sarifRule{
+			ID:                   rule.ID,
+			Name:                 rule.Name,
+			ShortDescription:     sarifMessage{rule.Description},
+			DefaultConfiguration: sarifConfiguration{"warning"},
+		}
+	}
+
+	fingerprints := Fingerprints(findings)
+	for i, f := range findings {
+		index, rule := RuleOf(f.Op)
+		result := sarifResult{
+			RuleID:              rule.ID,
+			RuleIndex:           index,
+			Level:               f.Level(),
+			Message:             sarifMessage{f.Message()},
+			Locations:           []sarifLocation{physicalLocation(f.Pos, f.End, root, "")},
+			PartialFingerprints: map[string]string{"paramguardFingerprint/v1": fingerprints[i]},
+		}
+		if f.Declared.IsValid() {
+			decl := physicalLocation(f.Declared, token.Position{}, root, "Declared '"+f.Param+"' of "+f.Func)
+			decl.ID = 1
+			result.RelatedLocations = append(result.RelatedLocations, decl)
+		}
+		if f.Confirmed && f.Use.IsValid() {
+			use := physicalLocation(f.Use, token.Position{}, root, "unsafely used here")
+			use.ID = 2
+			result.RelatedLocations = append(result.RelatedLocations, use)
+		}
+		if len(f.CallPaths) > 0 {
+			result.CodeFlows = []sarifCodeFlow{codeFlow(f, root)}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{Version: sarifVersion, Schema: sarifSchema, Runs: []sarifRun{run}})
+}
+
+// codeFlow follows each feasible call path, as a thread flow, from its outermost caller down to the function and its use
+func codeFlow(f *Finding, root string) sarifCodeFlow {
+	var flow sarifCodeFlow
+	for _, path := range f.CallPaths {
+		var locations []sarifThreadFlowLocation
+		for i := len(path) - 1; i >= 0; i-- {
+			locations = append(locations, sarifThreadFlowLocation{sarifLocation{
+				LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: path[i], Kind: "function"}},
+				Message:          &sarifMessage{"calls"},
+			}})
+		}
+		use := physicalLocation(f.Use, token.Position{}, root, "unsafely used in "+f.Func)
+		use.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: f.Func, Kind: "function"}}
+		locations = append(locations, sarifThreadFlowLocation{use})
+		flow.ThreadFlows = append(flow.ThreadFlows, sarifThreadFlow{Locations: locations})
+	}
+	return flow
+}
+
+func physicalLocation(pos, end token.Position, root, msg string) sarifLocation {
+	artifact := sarifArtifact{URI: fileURI(pos.Filename)}
+	if rel, ok := relPath(root, pos.Filename); ok {
+		artifact = sarifArtifact{URI: (&url.URL{Path: rel}).String(), URIBaseID: srcRoot}
+	}
+	region := sarifRegion{StartLine: pos.Line, StartColumn: pos.Column}
+	if end.IsValid() {
+		region.EndLine, region.EndColumn = end.Line, end.Column
+	}
+	location := sarifLocation{PhysicalLocation: &sarifPhysicalLocation{artifact, region}}
+	if msg != "" {
+		location.Message = &sarifMessage{msg}
+	}
+	return location
+}
+
+// relPath returns the slash-separated path of the file relative to `root`, if it is under `root`
+func relPath(root, filename string) (string, bool) {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	rel, err := filepath.Rel(root, filename)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(filename), false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+func fileURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
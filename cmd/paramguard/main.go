@@ -1,11 +1,118 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/hyunsooda/paramguard/checker/driver"
 	"github.com/hyunsooda/paramguard/checker/passes"
+	"github.com/hyunsooda/paramguard/checker/report"
 	"golang.org/x/tools/go/analysis/singlechecker"
 )
 
+const (
+	FORMAT_TEXT  = "text"
+	FORMAT_SARIF = "sarif"
+	FORMAT_JSON  = "json"
+	FORMAT_JSONL = "jsonl"
+
+	FORMAT_JUNIT       = "junit"
+	FORMAT_CHECKSTYLE  = "checkstyle"
+	FORMAT_CODEQUALITY = "codequality" // GitLab Code Quality
+
+	FORMAT_HTML = "html"
+)
+
+const (
+	formatUsage = "Output format: text, sarif, json, jsonl, junit, checkstyle, codequality or html (default=text)"
+	outUsage    = "Write the report to the file instead of stdout, except for the text format (default=none)"
+)
+
+// frameworkFlags are the flags of the driver of the analysis framework, by whether they take a value
+var frameworkFlags = map[string]bool{
+	"flags": false, "V": false, "json": false, "fix": false, "test": false, "source": false, "v": false, "all": false,
+	"c": true, "debug": true, "cpuprofile": true, "memprofile": true, "trace": true, "tags": true,
+}
+
 func main() {
 	passes.Init()
-	singlechecker.Main(passes.MainAnalyzer)
+	log.SetFlags(0)
+	log.SetPrefix(passes.MainAnalyzer.Name + ": ")
+
+	format, out, patterns, err := parseArgs(os.Args[1:])
+	if err != nil || format == FORMAT_TEXT {
+		if err == nil && out != "" {
+			log.Fatal("-out is not supported by the text format")
+		}
+		// The driver of the analysis framework reports the findings as its diagnostics, or the parse error
+		flag.String("format", FORMAT_TEXT, formatUsage)
+		flag.String("out", "", outUsage)
+		singlechecker.Main(passes.MainAnalyzer)
+		return
+	}
+
+	findings, err := driver.Run(context.Background(), driver.Config{}, patterns...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var w io.Writer = os.Stdout
+	if out != "" {
+		file, err := os.Create(out)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		w = file
+	}
+	if err := write(w, format, findings); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func write(w io.Writer, format string, findings []*report.Finding) error {
+	switch format {
+	case FORMAT_SARIF:
+		return report.WriteSARIF(w, findings, ".")
+	case FORMAT_JSON:
+		return report.WriteJSON(w, findings)
+	case FORMAT_JSONL:
+		return report.WriteJSONLines(w, findings)
+	case FORMAT_JUNIT:
+		return report.WriteJUnit(w, findings, ".")
+	case FORMAT_CHECKSTYLE:
+		return report.WriteCheckstyle(w, findings, ".")
+	case FORMAT_CODEQUALITY:
+		return report.WriteCodeQuality(w, findings, ".")
+	case FORMAT_HTML:
+		return report.WriteHTML(w, findings, ".")
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+// parseArgs parses the arguments before choosing the driver, since the text format leaves them to the driver
+// of the analysis framework. Its flags are declared as well, so that the values they take are not mistaken
+// for the patterns, while the flags of the analyzer are set for the other formats.
+func parseArgs(args []string) (format, out string, patterns []string, err error) {
+	fs := flag.NewFlagSet(passes.MainAnalyzer.Name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&format, "format", FORMAT_TEXT, formatUsage)
+	fs.StringVar(&out, "out", "", outUsage)
+	passes.MainAnalyzer.Flags.VisitAll(func(f *flag.Flag) {
+		fs.Var(f.Value, f.Name, f.Usage)
+	})
+	for name, hasValue := range frameworkFlags {
+		if hasValue {
+			fs.String(name, "", "")
+		} else {
+			fs.Bool(name, false, "")
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return "", "", nil, err
+	}
+	return format, out, fs.Args(), nil
 }
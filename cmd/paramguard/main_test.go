@@ -0,0 +1,40 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hyunsooda/paramguard/checker/passes"
+)
+
+func TestParseArgs(t *testing.T) {
+	passes.Init()
+
+	tcs := []struct {
+		args     []string
+		format   string
+		out      string
+		patterns []string
+	}{
+		{[]string{"./..."}, FORMAT_TEXT, "", []string{"./..."}},
+		{[]string{"-format=sarif", "./..."}, FORMAT_SARIF, "", []string{"./..."}},
+		{[]string{"-config", "c.yml", "-format", "sarif", "./..."}, FORMAT_SARIF, "", []string{"./..."}},
+		{[]string{"-c", "3", "--format", "json", "-out", "r.json", "a", "b"}, FORMAT_JSON, "r.json", []string{"a", "b"}},
+		{[]string{"-receivers", "-format", "html", "--", "-pkg"}, FORMAT_HTML, "", []string{"-pkg"}},
+		{[]string{"./...", "-format", "sarif"}, FORMAT_TEXT, "", []string{"./...", "-format", "sarif"}},
+	}
+	for _, tc := range tcs {
+		format, out, patterns, err := parseArgs(tc.args)
+		if err != nil {
+			t.Errorf("%v: %v", tc.args, err)
+			continue
+		}
+		if format != tc.format || out != tc.out || !reflect.DeepEqual(patterns, tc.patterns) {
+			t.Errorf("%v: parsed %q, %q, %q, expected %q, %q, %q", tc.args, format, out, patterns, tc.format, tc.out, tc.patterns)
+		}
+	}
+
+	if _, _, _, err := parseArgs([]string{"-unknown", "./..."}); err == nil {
+		t.Errorf("unknown flag accepted")
+	}
+}
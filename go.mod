@@ -2,13 +2,12 @@ module github.com/hyunsooda/paramguard
 
 go 1.20
 
-require golang.org/x/tools v0.11.0
+require (
+	golang.org/x/tools v0.11.0
+	gopkg.in/yaml.v2 v2.4.0
+)
 
 require (
-	github.com/fatih/color v1.15.0 // indirect
-	github.com/mattn/go-colorable v0.1.13 // indirect
-	github.com/mattn/go-isatty v0.0.17 // indirect
 	golang.org/x/mod v0.12.0 // indirect
 	golang.org/x/sys v0.10.0 // indirect
-	gopkg.in/yaml.v2 v2.4.0 // indirect
 )
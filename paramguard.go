@@ -0,0 +1,37 @@
+// Package paramguard runs ParameterGuard as a library, e.g., from bots consuming its findings without the command.
+//
+//	findings, err := paramguard.Analyze(ctx, []string{"./..."}, paramguard.Options{Dir: repo})
+//	...
+//	err = report.WriteJSON(os.Stdout, findings) // or consume the findings directly
+package paramguard
+
+import (
+	"context"
+
+	"github.com/hyunsooda/paramguard/checker/driver"
+	"github.com/hyunsooda/paramguard/checker/passtyps"
+	"github.com/hyunsooda/paramguard/checker/report"
+)
+
+// Finding is an unguarded use of a nilable parameter, or a call site confirmed to pass nil to one
+type Finding = report.Finding
+
+// Config configures the checker, as the configuration file of the command does
+type Config = passtyps.Config
+
+// Options of an analysis
+type Options struct {
+	Dir    string   // directory to load the packages from, the current directory if empty
+	Env    []string // environment of the build system, the current one if nil
+	Config *Config  // configuration of the checker, the defaults if nil
+}
+
+// Analyze checks the packages matching the patterns, e.g., "./...", returning their findings sorted by position.
+// With Config.CallGraph, the call paths are those through the matched packages, loaded from Options.Dir.
+func Analyze(ctx context.Context, patterns []string, opts Options) ([]*Finding, error) {
+	config := opts.Config
+	if config == nil {
+		config = &Config{}
+	}
+	return driver.Run(ctx, driver.Config{Dir: opts.Dir, Env: opts.Env, Checker: config}, patterns...)
+}
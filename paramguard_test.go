@@ -0,0 +1,312 @@
+package paramguard_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hyunsooda/paramguard"
+	"github.com/hyunsooda/paramguard/checker/passes"
+	"github.com/hyunsooda/paramguard/checker/report"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func options(t *testing.T, pkg string, config *paramguard.Config) paramguard.Options {
+	testdata, err := filepath.Abs(filepath.Join("checker", "passes", "testdata"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return paramguard.Options{
+		Dir:    filepath.Join(testdata, "src", pkg),
+		Env:    append(os.Environ(), "GOPATH="+testdata, "GO111MODULE=off", "GOPROXY=off"),
+		Config: config,
+	}
+}
+
+func analyze(t *testing.T, pkg string, config *paramguard.Config) []*paramguard.Finding {
+	findings, err := paramguard.Analyze(context.Background(), []string{"."}, options(t, pkg, config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return findings
+}
+
+func TestAnalyze(t *testing.T) {
+	defaults := analyze(t, "elements", nil)
+	elements := analyze(t, "elements", &paramguard.Config{Elements: true})
+	if len(elements) <= len(defaults) {
+		t.Errorf("%d findings checking the elements, want more than the %d by default", len(elements), len(defaults))
+	}
+
+	// Concurrent analyses keep their own configurations
+	configs := []*paramguard.Config{nil, {Elements: true}}
+	counts := make([]int, len(configs))
+	errs := make([]error, len(configs))
+	var wg sync.WaitGroup
+	for i, config := range configs {
+		wg.Add(1)
+		go func(i int, opts paramguard.Options) {
+			defer wg.Done()
+			findings, err := paramguard.Analyze(context.Background(), []string{"."}, opts)
+			counts[i], errs[i] = len(findings), err
+		}(i, options(t, "elements", config))
+	}
+	wg.Wait()
+	for i, want := range []int{len(defaults), len(elements)} {
+		if errs[i] != nil || counts[i] != want {
+			t.Errorf("concurrent analysis %d: %d findings (%v), want %d", i, counts[i], errs[i], want)
+		}
+	}
+
+	if _, err := paramguard.Analyze(context.Background(), []string{"."}, paramguard.Options{Config: &paramguard.Config{Callers: "dorp"}}); err == nil || !strings.Contains(err.Error(), `unknown callers "dorp"`) {
+		t.Errorf("analyzing with unknown callers: %v, want an error", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := paramguard.Analyze(ctx, []string{"."}, paramguard.Options{}); err == nil {
+		t.Error("no error analyzing with a canceled context")
+	}
+}
+
+// Each finding is reported once, as a single diagnostic
+func TestDiagnostics(t *testing.T) {
+	passes.Init()
+	testdata, err := filepath.Abs(filepath.Join("checker", "passes", "testdata"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pkg := range []string{"slice", "struct"} {
+		diagnostics := analysistest.Run(t, testdata, passes.MainAnalyzer, pkg)[0].Diagnostics
+		if findings := analyze(t, pkg, nil); len(findings) != len(diagnostics) {
+			t.Errorf("%s: %d findings, want %d as the diagnostics", pkg, len(findings), len(diagnostics))
+		}
+	}
+}
+
+// The call paths are those of the analyzed packages, each from the direct caller of the function outward
+func TestCallPaths(t *testing.T) {
+	analyze(t, "branch", &paramguard.Config{CallGraph: true})
+
+	for _, tc := range []struct {
+		maxpath int
+		paths   [][]string
+	}{
+		{0, [][]string{
+			{"callpaths.a", "callpaths.main"},
+			{"callpaths.b", "callpaths.c"},
+			{"callpaths.b", "callpaths.main"},
+			{"callpaths.recursive", "callpaths.main"},
+		}},
+		{1, [][]string{{"callpaths.a"}, {"callpaths.b"}, {"callpaths.recursive"}}},
+	} {
+		findings := analyze(t, "callpaths", &paramguard.Config{CallGraph: true, Maxpath: tc.maxpath})
+		if len(findings) != 1 || !reflect.DeepEqual(findings[0].CallPaths, tc.paths) {
+			t.Fatalf("maxpath %d: %v, want a finding with the paths %v", tc.maxpath, findings, tc.paths)
+		}
+	}
+
+	findings := analyze(t, "callpaths", &paramguard.Config{CallGraph: true})
+	var buf bytes.Buffer
+	if err := report.WriteSARIF(&buf, findings, "."); err != nil {
+		t.Fatal(err)
+	}
+	var log struct {
+		Runs []struct {
+			Results []struct {
+				CodeFlows []struct {
+					ThreadFlows []struct {
+						Locations []struct {
+							Location struct {
+								LogicalLocations []struct{ FullyQualifiedName string }
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	flows := log.Runs[0].Results[0].CodeFlows
+	if len(flows) != 1 || len(flows[0].ThreadFlows) != len(findings[0].CallPaths) {
+		t.Fatalf("%d code flows, want one with a thread flow per call path", len(flows))
+	}
+	for i, flow := range flows[0].ThreadFlows {
+		var names []string
+		for _, location := range flow.Locations {
+			names = append(names, location.Location.LogicalLocations[0].FullyQualifiedName)
+		}
+		path := findings[0].CallPaths[i]
+		if want := []string{path[1], path[0], "callpaths.use"}; !reflect.DeepEqual(names, want) {
+			t.Errorf("thread flow %d: %v, want %v", i, names, want)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	findings := analyze(t, "confirmed", nil)
+
+	var buf bytes.Buffer
+	if err := report.WriteJSON(&buf, findings); err != nil {
+		t.Fatal(err)
+	}
+	var result report.JSONReport
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Version != report.JSONVersion || len(result.Findings) != len(findings) {
+		t.Fatalf("version %d with %d findings, want version %d with %d", result.Version, len(result.Findings), report.JSONVersion, len(findings))
+	}
+
+	buf.Reset()
+	if err := report.WriteJSONLines(&buf, findings); err != nil {
+		t.Fatal(err)
+	}
+	scanner := bufio.NewScanner(&buf)
+	lines := 0
+	for ; scanner.Scan() && lines < len(result.Findings); lines++ {
+		var f report.JSONFinding
+		if err := json.Unmarshal(scanner.Bytes(), &f); err != nil {
+			t.Fatal(err)
+		}
+		want := result.Findings[lines]
+		if f.Version != report.JSONVersion || f.Fingerprint != want.Fingerprint || f.Pos != want.Pos {
+			t.Errorf("line %d: %+v, want %+v", lines, f, want)
+		}
+	}
+	if lines != len(findings) {
+		t.Errorf("%d lines, want %d", lines, len(findings))
+	}
+}
+
+func TestCIFormats(t *testing.T) {
+	findings := append(analyze(t, "confirmed", nil), analyze(t, "pointer", nil)...)
+	root, err := filepath.Abs(filepath.Join("checker", "passes", "testdata", "src"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteJUnit(&buf, findings, root); err != nil {
+		t.Fatal(err)
+	}
+	var junit struct {
+		Failures int `xml:"failures,attr"`
+		Suites   []struct {
+			Name  string `xml:"name,attr"`
+			Cases []struct {
+				Failure struct {
+					Type string `xml:"type,attr"`
+				} `xml:"failure"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &junit); err != nil {
+		t.Fatal(err)
+	}
+	if junit.Failures != len(findings) || len(junit.Suites) != 2 || junit.Suites[0].Name != "confirmed" || junit.Suites[1].Name != "pointer" {
+		t.Errorf("%d failures in %d suites, want %d in the suites of the 2 packages", junit.Failures, len(junit.Suites), len(findings))
+	}
+
+	buf.Reset()
+	if err := report.WriteCheckstyle(&buf, findings, root); err != nil {
+		t.Fatal(err)
+	}
+	var checkstyle struct {
+		Files []struct {
+			Name   string `xml:"name,attr"`
+			Errors []struct {
+				Line     int    `xml:"line,attr"`
+				Severity string `xml:"severity,attr"`
+			} `xml:"error"`
+		} `xml:"file"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &checkstyle); err != nil {
+		t.Fatal(err)
+	}
+	errors := 0
+	for _, file := range checkstyle.Files {
+		if filepath.IsAbs(file.Name) {
+			t.Errorf("file %s is not relative to the root", file.Name)
+		}
+		for _, e := range file.Errors {
+			if e.Line == 0 || e.Severity == "" {
+				t.Errorf("%s: unlocated or unclassified error %+v", file.Name, e)
+			}
+			errors++
+		}
+	}
+	if errors != len(findings) {
+		t.Errorf("%d errors, want %d", errors, len(findings))
+	}
+
+	buf.Reset()
+	if err := report.WriteCodeQuality(&buf, findings, root); err != nil {
+		t.Fatal(err)
+	}
+	var issues []struct {
+		Fingerprint string
+		Severity    string
+		Location    struct {
+			Path  string
+			Lines struct{ Begin int }
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &issues); err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != len(findings) {
+		t.Fatalf("%d issues, want %d", len(issues), len(findings))
+	}
+	fingerprints := make(map[string]bool)
+	for _, issue := range issues {
+		if issue.Fingerprint == "" || fingerprints[issue.Fingerprint] || issue.Severity == "" || filepath.IsAbs(issue.Location.Path) || issue.Location.Lines.Begin == 0 {
+			t.Errorf("invalid issue %+v", issue)
+		}
+		fingerprints[issue.Fingerprint] = true
+	}
+}
+
+func TestHTML(t *testing.T) {
+	findings := append(analyze(t, "branch", nil), analyze(t, "confirmed", nil)...)
+	findings = append(findings, analyze(t, "callpaths", &paramguard.Config{CallGraph: true})...)
+	var buf bytes.Buffer
+	if err := report.WriteHTML(&buf, findings, "."); err != nil {
+		t.Fatal(err)
+	}
+	html := buf.String()
+	for _, want := range []string{
+		`<section class="pkg" id="p0" data-pkg="branch">`, // per package
+		`<li class="nav-func" data-target="p1-0">`,        // per function
+		`<div class="decl">`,                              // the snippets of the declaration, the guard and the use
+		`<div class="guard">`,
+		`<div class="use">`,
+		`<span class="kw">nil</span>`,                                // highlighted
+		`<input type="checkbox" class="rule" value="PG004" checked>`, // filters
+		`<option>confirmed</option>`,
+		// the call paths merged into the tree of the callers
+		`<details class="tree" open><summary>callpaths.use</summary>` +
+			`<details class="tree" open><summary>callpaths.a</summary><div class="tree">callpaths.main</div></details>` +
+			`<details class="tree" open><summary>callpaths.b</summary><div class="tree">callpaths.c</div><div class="tree">callpaths.main</div></details>` +
+			`<details class="tree" open><summary>callpaths.recursive</summary><div class="tree">callpaths.main</div></details></details>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("the report lacks %s", want)
+		}
+	}
+	for _, external := range []string{"<link", "<script src", "<img"} {
+		if strings.Contains(html, external) {
+			t.Errorf("the report loads an external resource with %s", external)
+		}
+	}
+}